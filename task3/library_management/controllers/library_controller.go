@@ -3,30 +3,35 @@ package controllers
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"library_management/models"
 	"library_management/services"
-	"os"
 	"strconv"
 	"strings"
 )
 
-// LibraryController provides a console interface to interact with the library.
-func LibraryController(library *services.Library) {
-	reader := bufio.NewReader(os.Stdin)
+// LibraryController provides a console interface to interact with the
+// library, reading choices from in until Exit or the end of input.
+func LibraryController(library *services.Library, in io.Reader) {
+	reader := bufio.NewReader(in)
 	for {
 		fmt.Println("\n--- Library Management System ---")
 		fmt.Println("1. Add Book")
 		fmt.Println("2. Remove Book")
 		fmt.Println("3. Borrow Book")
 		fmt.Println("4. Return Book")
-		fmt.Println("5. List Available Books")
-		fmt.Println("6. List Borrowed Books by Member")
-		fmt.Println("7. Add Member")
-		fmt.Println("8. List All Books")
-		fmt.Println("9. Exit")
+		fmt.Println("5. Reserve Book")
+		fmt.Println("6. List Available Books")
+		fmt.Println("7. List Borrowed Books by Member")
+		fmt.Println("8. Add Member")
+		fmt.Println("9. List All Books")
+		fmt.Println("10. Exit")
 		fmt.Print("Enter your choice: ")
 
-		input, _ := reader.ReadString('\n')
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			return
+		}
 		choice, err := strconv.Atoi(strings.TrimSpace(input))
 		if err != nil {
 			fmt.Println("Invalid input, please enter a number.")
@@ -43,14 +48,16 @@ func LibraryController(library *services.Library) {
 		case 4:
 			returnBook(reader, library)
 		case 5:
-			listAvailableBooks(library)
+			reserveBook(reader, library)
 		case 6:
-			listBorrowedBooks(reader, library)
+			listAvailableBooks(library)
 		case 7:
-			addMember(reader, library)
+			listBorrowedBooks(reader, library)
 		case 8:
-			library.ListAllBooks()
+			addMember(reader, library)
 		case 9:
+			listAllBooks(library)
+		case 10:
 			fmt.Println("Exiting...")
 			return
 		default:
@@ -80,9 +87,12 @@ func addBook(reader *bufio.Reader, library *services.Library) {
 		ID:     id,
 		Title:  title,
 		Author: author,
-		Status: "Available",
+		Status: models.StatusAvailable,
+	}
+	if err := library.AddBook(book); err != nil {
+		fmt.Println("Error:", err)
+		return
 	}
-	library.AddBook(book)
 	fmt.Println("Book added successfully!")
 }
 
@@ -94,7 +104,10 @@ func removeBook(reader *bufio.Reader, library *services.Library) {
 		fmt.Println("Invalid ID")
 		return
 	}
-	library.RemoveBook(id)
+	if err := library.RemoveBook(id); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 	fmt.Println("Book removed successfully!")
 }
 
@@ -146,6 +159,30 @@ func returnBook(reader *bufio.Reader, library *services.Library) {
 	}
 }
 
+func reserveBook(reader *bufio.Reader, library *services.Library) {
+	fmt.Print("Enter Book ID to reserve: ")
+	bookIDStr, _ := reader.ReadString('\n')
+	bookID, err := strconv.Atoi(strings.TrimSpace(bookIDStr))
+	if err != nil {
+		fmt.Println("Invalid Book ID")
+		return
+	}
+
+	fmt.Print("Enter Member ID: ")
+	memberIDStr, _ := reader.ReadString('\n')
+	memberID, err := strconv.Atoi(strings.TrimSpace(memberIDStr))
+	if err != nil {
+		fmt.Println("Invalid Member ID")
+		return
+	}
+
+	if err := library.ReserveBook(bookID, memberID); err != nil {
+		fmt.Println("Error:", err)
+	} else {
+		fmt.Printf("Book reserved! Borrow it within %s or the reservation will expire.\n", services.ReservationHold)
+	}
+}
+
 func listAvailableBooks(library *services.Library) {
 	books := library.ListAvailableBooks()
 	if len(books) == 0 {
@@ -158,6 +195,18 @@ func listAvailableBooks(library *services.Library) {
 	}
 }
 
+func listAllBooks(library *services.Library) {
+	books := library.ListBooks()
+	if len(books) == 0 {
+		fmt.Println("No books in the library.")
+		return
+	}
+	fmt.Println("All Books:")
+	for _, book := range books {
+		fmt.Printf("ID: %d, Title: %s, Author: %s, Status: %s\n", book.ID, book.Title, book.Author, book.Status)
+	}
+}
+
 func listBorrowedBooks(reader *bufio.Reader, library *services.Library) {
 	fmt.Print("Enter Member ID: ")
 	memberIDStr, _ := reader.ReadString('\n')
@@ -191,9 +240,9 @@ func addMember(reader *bufio.Reader, library *services.Library) {
 	name = strings.TrimSpace(name)
 
 	member := models.Member{
-		ID:            id,
-		Name:          name,
-		BorrowedBooks: []models.Book{},
+		ID:    id,
+		Name:  name,
+		Loans: []models.Loan{},
 	}
 	library.AddMember(member)
 	fmt.Println("Member added successfully!")
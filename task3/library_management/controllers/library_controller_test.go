@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"library_management/clock"
+	"library_management/events"
+	"library_management/models"
+	"library_management/services"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLibrary returns a library on a fake clock with members 1 and 2 and
+// books 101 and 102.
+func newTestLibrary(t *testing.T) (*services.Library, *clock.Fake) {
+	t.Helper()
+	fake := clock.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	library := services.NewLibraryWithClock(fake)
+	t.Cleanup(library.Close)
+	library.AddMember(models.Member{ID: 1, Name: "Alice", Loans: []models.Loan{}})
+	library.AddMember(models.Member{ID: 2, Name: "Bob", Loans: []models.Loan{}})
+	for _, book := range []models.Book{
+		{ID: 101, Title: "Go Programming", Author: "John Doe"},
+		{ID: 102, Title: "Introducing Go", Author: "Caleb Doxsey"},
+	} {
+		if err := library.AddBook(book); err != nil {
+			t.Fatalf("AddBook: %v", err)
+		}
+	}
+	return library, fake
+}
+
+// run feeds the menu choices and their answers to the controller, one per
+// line, and returns once it has read them all.
+func run(library *services.Library, lines ...string) {
+	LibraryController(library, strings.NewReader(strings.Join(lines, "\n")+"\n"))
+}
+
+func mustBook(t *testing.T, library *services.Library, bookID int) models.Book {
+	t.Helper()
+	book, err := library.GetBook(bookID)
+	if err != nil {
+		t.Fatalf("GetBook(%d): %v", bookID, err)
+	}
+	return book
+}
+
+// waitFor returns the next event of type typ.
+func waitFor(t *testing.T, ch <-chan events.Event, typ events.Type) events.Event {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case e := <-ch:
+			if e.Type == typ {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", typ)
+		}
+	}
+}
+
+func TestReservationExpires(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	expired, _ := library.Subscribe()
+
+	run(library, "5", "101", "1") // Reserve book 101 for member 1
+	if book := mustBook(t, library, 101); book.Status != models.StatusReserved || book.ReservedBy != 1 {
+		t.Fatalf("after reserving: status %s, reserved by %d", book.Status, book.ReservedBy)
+	}
+
+	run(library, "3", "101", "2") // Bob cannot borrow Alice's reservation
+	if books := library.ListBorrowedBooks(2); len(books) != 0 {
+		t.Fatalf("member 2 borrowed a book reserved for member 1: %+v", books)
+	}
+
+	fake.Advance(services.ReservationHold)
+	if book := mustBook(t, library, 101); book.Status != models.StatusAvailable || book.ReservedBy != 0 {
+		t.Fatalf("after the hold: status %s, reserved by %d", book.Status, book.ReservedBy)
+	}
+	if e := waitFor(t, expired, events.ReservationExpired); e.BookID != 101 || e.MemberID != 1 {
+		t.Errorf("expired reservation of book %d by member %d, want 101 by 1", e.BookID, e.MemberID)
+	}
+
+	run(library, "3", "101", "2", "10") // Anyone may borrow it now
+	if books := library.ListBorrowedBooks(2); len(books) != 1 || books[0].ID != 101 {
+		t.Fatalf("member 2 borrowed %+v, want book 101", books)
+	}
+}
+
+func TestBorrowingReservationStopsExpiry(t *testing.T) {
+	library, fake := newTestLibrary(t)
+
+	run(library, "5", "102", "1", "3", "102", "1")
+	fake.Advance(2 * services.ReservationHold)
+
+	if book := mustBook(t, library, 102); book.Status != models.StatusBorrowed {
+		t.Fatalf("status %s after the hold would have expired, want %s", book.Status, models.StatusBorrowed)
+	}
+	if books := library.ListBorrowedBooks(1); len(books) != 1 {
+		t.Fatalf("member 1 borrowed %+v, want book 102", books)
+	}
+	if n := fake.Pending(); n != 0 {
+		t.Errorf("%d reservation timers still pending", n)
+	}
+}
+
+func TestAddAndRemoveBooks(t *testing.T) {
+	library, _ := newTestLibrary(t)
+
+	run(library,
+		"1", "103", "Clean Code", "Robert C. Martin",
+		"1", "101", "Duplicate", "Nobody", // Rejected: ID in use
+		"3", "102", "1",
+		"2", "102", // Rejected: on loan
+		"2", "103",
+	)
+	if book := mustBook(t, library, 101); book.Title != "Go Programming" {
+		t.Errorf("book 101 title %q, want it unchanged", book.Title)
+	}
+	if _, err := library.GetBook(102); err != nil {
+		t.Errorf("borrowed book 102 was removed: %v", err)
+	}
+	if _, err := library.GetBook(103); err == nil {
+		t.Error("book 103 was not removed")
+	}
+}
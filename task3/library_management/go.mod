@@ -1,3 +1,7 @@
-module library_management
+module library_console
 
-go 1.20
\ No newline at end of file
+go 1.22.2
+
+require library_management v0.0.0
+
+replace library_management => ../../task4/library_management
@@ -2,28 +2,39 @@ package main
 
 import (
 	"fmt"
-	"library_management/controllers"
+	"library_console/controllers"
+	"library_management/events"
 	"library_management/models"
 	"library_management/services"
+	"os"
 )
 
 func main() {
 	library := services.NewLibrary()
-	
+	defer library.Close()
+
+	// Tell the console user when a reservation times out.
+	expiryEvents, _ := library.Subscribe()
+	go func() {
+		for e := range expiryEvents {
+			if e.Type == events.ReservationExpired {
+				fmt.Printf("Auto-cancellation: Reservation for book %d by member %d has timed out.\n", e.BookID, e.MemberID)
+			}
+		}
+	}()
 
-	
 	print([]models.Book{}) // output: []    // just empty slice or array of books
 	// print([]models.Book)  // this is data type and  so error will be seen
 	// Optionally, add an initial member for testing.
 	library.AddMember(models.Member{
-		ID:            1,
-		Name:          "Alice",
-		BorrowedBooks: []models.Book{},  // empty array of Book if to say []models.Book would be error cause this is type not a value 
+		ID:    1,
+		Name:  "Alice",
+		Loans: []models.Loan{}, // empty slice of Loan; []models.Loan alone would be an error because it is a type, not a value
 
 	})
 
 	// Start the console-based interface.
-	controllers.LibraryController(library)
+	controllers.LibraryController(library, os.Stdin)
 }
 
 
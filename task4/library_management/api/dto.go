@@ -0,0 +1,175 @@
+package api
+
+import (
+	"library_management/models"
+	"library_management/services"
+	"time"
+)
+
+// BookResponse is the JSON representation of a book.
+type BookResponse struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Status      string `json:"status"`
+	ReservedBy  int    `json:"reserved_by,omitempty"`
+	HomeBranch  int    `json:"home_branch"`
+	Branch      int    `json:"branch"`
+	Destination int    `json:"destination,omitempty"`
+}
+
+// RecommendationResponse is a recommended book with its score.
+type RecommendationResponse struct {
+	BookResponse
+	Score int `json:"score"` // Readers shared with the book or member's history
+}
+
+// BranchResponse is the JSON representation of a branch.
+type BranchResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// LoanResponse is the JSON representation of a loan.
+type LoanResponse struct {
+	BookID     int       `json:"book_id"`
+	Title      string    `json:"title"`
+	Author     string    `json:"author"`
+	BorrowedAt time.Time `json:"borrowed_at"`
+	DueDate    time.Time `json:"due_date"`
+}
+
+// MemberResponse is the JSON representation of a member.
+type MemberResponse struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	BorrowedBooks []int  `json:"borrowed_books"` // IDs of the books on loan
+	Suspended     bool   `json:"suspended"`
+}
+
+// CreateBookRequest is the body of POST /books.
+type CreateBookRequest struct {
+	ID         int    `json:"id" binding:"required,gt=0"`
+	Title      string `json:"title" binding:"required"`
+	Author     string `json:"author"`
+	HomeBranch int    `json:"home_branch" binding:"gte=0"` // Defaults to the main branch
+}
+
+// CreateBranchRequest is the body of POST /branches.
+type CreateBranchRequest struct {
+	ID   int    `json:"id" binding:"required,gt=0"`
+	Name string `json:"name" binding:"required"`
+}
+
+// BranchRequest is the body of transfer and receive requests.
+type BranchRequest struct {
+	BranchID int `json:"branch_id" binding:"required,gt=0"`
+}
+
+// CreateMemberRequest is the body of POST /members.
+type CreateMemberRequest struct {
+	ID   int    `json:"id" binding:"required,gt=0"`
+	Name string `json:"name" binding:"required"`
+}
+
+// UpdateStatusRequest is the body of PUT /books/:id/status.
+type UpdateStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// UpdateMemberRequest is the body of PUT /members/:id.
+type UpdateMemberRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// SuspendMemberRequest is the body of PUT /members/:id/suspended.
+type SuspendMemberRequest struct {
+	Suspended *bool `json:"suspended" binding:"required"`
+}
+
+// BookRequest is the body of loan and reservation requests. BranchID is
+// where the book is borrowed, or the pickup branch of a reservation; it
+// defaults to the branch where the book is.
+type BookRequest struct {
+	BookID   int `json:"book_id" binding:"required,gt=0"`
+	BranchID int `json:"branch_id" binding:"gte=0"`
+}
+
+// BatchRequest is the body of batch checkout and return requests.
+type BatchRequest struct {
+	BookIDs  []int `json:"book_ids" binding:"required,min=1,dive,gt=0"`
+	BranchID int   `json:"branch_id" binding:"gte=0"`
+}
+
+// ErrorResponse is returned with every non-2xx status.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// ItemErrorResponse is the reason one book of a batch was rejected.
+type ItemErrorResponse struct {
+	BookID int    `json:"book_id"`
+	Error  string `json:"error"`
+}
+
+// BatchErrorResponse is returned when a batch is rejected; none of its books
+// were processed.
+type BatchErrorResponse struct {
+	Error string              `json:"error"`
+	Items []ItemErrorResponse `json:"items"`
+}
+
+func toBookResponse(book models.Book) BookResponse {
+	return BookResponse{
+		ID:          book.ID,
+		Title:       book.Title,
+		Author:      book.Author,
+		Status:      string(book.Status),
+		ReservedBy:  book.ReservedBy,
+		HomeBranch:  book.HomeBranch,
+		Branch:      book.Branch,
+		Destination: book.Destination,
+	}
+}
+
+func toRecommendationResponses(recs []services.Recommendation) []RecommendationResponse {
+	res := make([]RecommendationResponse, 0, len(recs))
+	for _, rec := range recs {
+		res = append(res, RecommendationResponse{BookResponse: toBookResponse(rec.Book), Score: rec.Score})
+	}
+	return res
+}
+
+func toBranchResponses(branches []models.Branch) []BranchResponse {
+	res := make([]BranchResponse, 0, len(branches))
+	for _, branch := range branches {
+		res = append(res, BranchResponse{ID: branch.ID, Name: branch.Name})
+	}
+	return res
+}
+
+func toLoanResponse(loan models.Loan, book models.Book) LoanResponse {
+	return LoanResponse{
+		BookID:     loan.BookID,
+		Title:      book.Title,
+		Author:     book.Author,
+		BorrowedAt: loan.BorrowedAt,
+		DueDate:    loan.DueDate,
+	}
+}
+
+func toBookResponses(books []models.Book) []BookResponse {
+	res := make([]BookResponse, 0, len(books))
+	for _, book := range books {
+		res = append(res, toBookResponse(book))
+	}
+	return res
+}
+
+func toMemberResponse(member models.Member) MemberResponse {
+	ids := make([]int, 0, len(member.Loans))
+	for _, loan := range member.Loans {
+		ids = append(ids, loan.BookID)
+	}
+	return MemberResponse{ID: member.ID, Name: member.Name, BorrowedBooks: ids, Suspended: member.Suspended}
+}
@@ -0,0 +1,533 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"library_management/auth"
+	"library_management/concurrency"
+	"library_management/models"
+	"library_management/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// commandTimeout bounds how long a request waits for the command workers.
+const commandTimeout = 3 * time.Second
+
+// Handler serves the library over HTTP.
+type Handler struct {
+	Library  services.LibraryManager
+	Pool     *concurrency.CommandPool
+	Accounts *auth.Store // Member logins, removed along with the members
+}
+
+// NewHandler creates a new Handler instance.
+func NewHandler(library services.LibraryManager, pool *concurrency.CommandPool, accounts *auth.Store) *Handler {
+	return &Handler{
+		Library:  library,
+		Pool:     pool,
+		Accounts: accounts,
+	}
+}
+
+// ListBooks handles GET /books. With ?available=true only available books are returned.
+func (h *Handler) ListBooks(c *gin.Context) {
+	if c.Query("available") == "true" {
+		c.JSON(http.StatusOK, toBookResponses(h.Library.ListAvailableBooks()))
+		return
+	}
+	c.JSON(http.StatusOK, toBookResponses(h.Library.ListBooks()))
+}
+
+// SearchBooks handles GET /books/search?q=...
+func (h *Handler) SearchBooks(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "query parameter q is required"})
+		return
+	}
+	c.JSON(http.StatusOK, toBookResponses(h.Library.SearchBooks(query)))
+}
+
+// GetBook handles GET /books/:id.
+func (h *Handler) GetBook(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	book, err := h.Library.GetBook(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toBookResponse(book))
+}
+
+// CreateBook handles POST /books.
+func (h *Handler) CreateBook(c *gin.Context) {
+	var req CreateBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+	if req.HomeBranch != 0 && !h.branchExists(req.HomeBranch) {
+		writeError(c, services.ErrBranchNotFound)
+		return
+	}
+
+	book := models.Book{ID: req.ID, Title: req.Title, Author: req.Author, HomeBranch: req.HomeBranch}
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandAddBook, Book: book}); err != nil {
+		writeError(c, err)
+		return
+	}
+	book, err := h.Library.GetBook(req.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toBookResponse(book))
+}
+
+// DeleteBook handles DELETE /books/:id.
+func (h *Handler) DeleteBook(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandRemoveBook, BookID: id}); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateBookStatus handles PUT /books/:id/status, e.g. {"status": "Lost"}.
+func (h *Handler) UpdateBookStatus(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+	status, err := models.ParseBookStatus(req.Status)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandUpdateStatus, BookID: id, Status: status}); err != nil {
+		writeError(c, err)
+		return
+	}
+	book, err := h.Library.GetBook(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toBookResponse(book))
+}
+
+// ListMembers handles GET /members.
+func (h *Handler) ListMembers(c *gin.Context) {
+	members := h.Library.ListMembers()
+	res := make([]MemberResponse, 0, len(members))
+	for _, member := range members {
+		res = append(res, toMemberResponse(member))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetMember handles GET /members/:id.
+func (h *Handler) GetMember(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	member, err := h.Library.GetMember(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toMemberResponse(member))
+}
+
+// CreateMember handles POST /members.
+func (h *Handler) CreateMember(c *gin.Context) {
+	var req CreateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+
+	member := models.Member{ID: req.ID, Name: req.Name, Loans: []models.Loan{}}
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandAddMember, Member: member}); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toMemberResponse(member))
+}
+
+// UpdateMember handles PUT /members/:id.
+func (h *Handler) UpdateMember(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandUpdateMember, MemberID: id, Name: req.Name}); err != nil {
+		writeError(c, err)
+		return
+	}
+	h.GetMember(c)
+}
+
+// SuspendMember handles PUT /members/:id/suspended, e.g. {"suspended": true}.
+func (h *Handler) SuspendMember(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req SuspendMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+	cmd := concurrency.Command{Kind: concurrency.CommandSuspend, MemberID: id, Suspended: *req.Suspended}
+	if err := h.do(c, cmd); err != nil {
+		writeError(c, err)
+		return
+	}
+	h.GetMember(c)
+}
+
+// DeleteMember handles DELETE /members/:id.
+func (h *Handler) DeleteMember(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandRemoveMember, MemberID: id}); err != nil {
+		writeError(c, err)
+		return
+	}
+	if err := h.Accounts.RemoveMember(id); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListLoans handles GET /members/:id/loans.
+func (h *Handler) ListLoans(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := h.Library.GetMember(id); err != nil {
+		writeError(c, err)
+		return
+	}
+	loans := h.Library.ListLoans(id)
+	res := make([]LoanResponse, 0, len(loans))
+	for _, loan := range loans {
+		book, err := h.Library.GetBook(loan.BookID)
+		if err != nil {
+			continue
+		}
+		res = append(res, toLoanResponse(loan, book))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// BorrowBook handles POST /members/:id/loans.
+func (h *Handler) BorrowBook(c *gin.Context) {
+	memberID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req BookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandBorrow, BookID: req.BookID, MemberID: memberID, Branch: req.BranchID}); err != nil {
+		writeError(c, err)
+		return
+	}
+	book, err := h.Library.GetBook(req.BookID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toBookResponse(book))
+}
+
+// ReturnBook handles DELETE /members/:id/loans/:bookId. With ?branch=<id>
+// the book is returned at that branch.
+func (h *Handler) ReturnBook(c *gin.Context) {
+	memberID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	bookID, ok := pathID(c, "bookId")
+	if !ok {
+		return
+	}
+	branchID := 0
+	if q := c.Query("branch"); q != "" {
+		id, err := strconv.Atoi(q)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid branch"})
+			return
+		}
+		branchID = id
+	}
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandReturn, BookID: bookID, MemberID: memberID, Branch: branchID}); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ReserveBook handles POST /members/:id/reservations.
+func (h *Handler) ReserveBook(c *gin.Context) {
+	memberID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req BookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+	if _, err := h.Library.GetMember(memberID); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandReserve, BookID: req.BookID, MemberID: memberID, Branch: req.BranchID}); err != nil {
+		writeError(c, err)
+		return
+	}
+	book, err := h.Library.GetBook(req.BookID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toBookResponse(book))
+}
+
+// BorrowBooks handles POST /members/:id/loans/batch, checking out all of
+// {"book_ids"} or none of them.
+func (h *Handler) BorrowBooks(c *gin.Context) {
+	h.batch(c, concurrency.CommandBorrowBatch, http.StatusCreated)
+}
+
+// ReturnBooks handles POST /members/:id/returns, returning all of
+// {"book_ids"} or none of them.
+func (h *Handler) ReturnBooks(c *gin.Context) {
+	h.batch(c, concurrency.CommandReturnBatch, http.StatusOK)
+}
+
+// batch runs a batch command and responds with the books involved. A
+// rejected batch is answered with 409 and the reason for each failing book.
+func (h *Handler) batch(c *gin.Context, kind concurrency.CommandKind, status int) {
+	memberID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+	err := h.do(c, concurrency.Command{Kind: kind, BookIDs: req.BookIDs, MemberID: memberID, Branch: req.BranchID})
+	var batchErr *services.BatchError
+	if errors.As(err, &batchErr) {
+		res := BatchErrorResponse{Error: "batch rejected; no books were processed"}
+		for _, item := range batchErr.Items {
+			res.Items = append(res.Items, ItemErrorResponse{BookID: item.BookID, Error: item.Err.Error()})
+		}
+		c.JSON(http.StatusConflict, res)
+		return
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	books := make([]models.Book, 0, len(req.BookIDs))
+	for _, id := range req.BookIDs {
+		if book, err := h.Library.GetBook(id); err == nil {
+			books = append(books, book)
+		}
+	}
+	c.JSON(status, toBookResponses(books))
+}
+
+// AlsoBorrowed handles GET /books/:id/also-borrowed. ?limit=n caps the
+// number of books returned.
+func (h *Handler) AlsoBorrowed(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	recs, err := h.Library.AlsoBorrowed(id, queryLimit(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRecommendationResponses(recs))
+}
+
+// Recommendations handles GET /members/:id/recommendations. ?limit=n caps
+// the number of books returned.
+func (h *Handler) Recommendations(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	recs, err := h.Library.RecommendFor(id, queryLimit(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRecommendationResponses(recs))
+}
+
+// queryLimit parses ?limit, returning 0 (the library default) if it is
+// missing or invalid.
+func queryLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// ListBranches handles GET /branches.
+func (h *Handler) ListBranches(c *gin.Context) {
+	c.JSON(http.StatusOK, toBranchResponses(h.Library.ListBranches()))
+}
+
+// CreateBranch handles POST /branches.
+func (h *Handler) CreateBranch(c *gin.Context) {
+	var req CreateBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+	if err := h.do(c, concurrency.Command{Kind: concurrency.CommandAddBranch, Branch: req.ID, Name: req.Name}); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, BranchResponse{ID: req.ID, Name: req.Name})
+}
+
+// TransferBook handles POST /books/:id/transfer, sending an available book
+// to {"branch_id"}.
+func (h *Handler) TransferBook(c *gin.Context) {
+	h.moveBook(c, concurrency.CommandTransfer)
+}
+
+// ReceiveBook handles POST /books/:id/receive, completing a transfer at
+// {"branch_id"}.
+func (h *Handler) ReceiveBook(c *gin.Context) {
+	h.moveBook(c, concurrency.CommandReceive)
+}
+
+func (h *Handler) moveBook(c *gin.Context, kind concurrency.CommandKind) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req BranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request payload"})
+		return
+	}
+	if err := h.do(c, concurrency.Command{Kind: kind, BookID: id, Branch: req.BranchID}); err != nil {
+		writeError(c, err)
+		return
+	}
+	book, err := h.Library.GetBook(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toBookResponse(book))
+}
+
+func (h *Handler) branchExists(id int) bool {
+	for _, branch := range h.Library.ListBranches() {
+		if branch.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// do runs a mutation through the command pool, like the console does, bounded
+// by the request's context and commandTimeout.
+func (h *Handler) do(c *gin.Context, cmd concurrency.Command) error {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), commandTimeout)
+	defer cancel()
+	return h.Pool.Do(ctx, cmd)
+}
+
+// pathID parses a numeric path parameter, writing a 400 response if it is invalid.
+func pathID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+// writeError maps library errors to HTTP status codes.
+func writeError(c *gin.Context, err error) {
+	c.JSON(statusFor(err), ErrorResponse{Error: err.Error()})
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, models.ErrUnknownStatus):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrBookNotFound),
+		errors.Is(err, services.ErrMemberNotFound),
+		errors.Is(err, services.ErrBranchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrBookReserved),
+		errors.Is(err, services.ErrBookBorrowed),
+		errors.Is(err, services.ErrBookNotAvailable),
+		errors.Is(err, services.ErrBookExists),
+		errors.Is(err, services.ErrMemberExists),
+		errors.Is(err, services.ErrBranchExists),
+		errors.Is(err, services.ErrNotBorrowedByMember),
+		errors.Is(err, models.ErrInvalidTransition),
+		errors.Is(err, services.ErrBookOnLoan),
+		errors.Is(err, services.ErrMemberHasLoans),
+		errors.Is(err, services.ErrWrongBranch),
+		errors.Is(err, services.ErrBookInTransit),
+		errors.Is(err, services.ErrNotInTransit):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrMemberSuspended):
+		return http.StatusForbidden
+	case errors.Is(err, concurrency.ErrRequestTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, concurrency.ErrPoolClosed), errors.Is(err, concurrency.ErrRequestCanceled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"library_management/auth"
+	"library_management/concurrency"
+	"library_management/models"
+	"library_management/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestRouter serves a library with members 1 (Alice) and 2 (Bob) and
+// books 101 and 102.
+func newTestRouter(t *testing.T) (*gin.Engine, *services.Library, *auth.Store) {
+	t.Helper()
+	library := services.NewLibrary()
+	t.Cleanup(library.Close)
+	library.AddMember(models.Member{ID: 1, Name: "Alice"})
+	library.AddMember(models.Member{ID: 2, Name: "Bob"})
+	library.AddBook(models.Book{ID: 101, Title: "Go Programming", Author: "John Doe"})
+	library.AddBook(models.Book{ID: 102, Title: "Concurrency in Go", Author: "Jane Roe"})
+	pool := concurrency.NewCommandPool(library, 2, 4)
+	t.Cleanup(func() { pool.Shutdown(context.Background()) })
+	accounts, err := auth.NewStore("")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	return SetupRouter(library, pool, accounts), library, accounts
+}
+
+func request(t *testing.T, r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestErrorStatusCodes(t *testing.T) {
+	r, library, _ := newTestRouter(t)
+	if err := library.BorrowBook(102, 1); err != nil {
+		t.Fatalf("BorrowBook: %v", err)
+	}
+	if err := library.SetMemberSuspended(2, true); err != nil {
+		t.Fatalf("SetMemberSuspended: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		error  string
+	}{
+		{"unknown book", http.MethodGet, "/books/999", "", http.StatusNotFound, services.ErrBookNotFound.Error()},
+		{"unknown member", http.MethodPost, "/members/9/loans", `{"book_id":101}`, http.StatusNotFound, services.ErrMemberNotFound.Error()},
+		{"duplicate book", http.MethodPost, "/books", `{"id":101,"title":"Again"}`, http.StatusConflict, services.ErrBookExists.Error()},
+		{"duplicate member", http.MethodPost, "/members", `{"id":1,"name":"Again"}`, http.StatusConflict, services.ErrMemberExists.Error()},
+		{"duplicate branch", http.MethodPost, "/branches", `{"id":1,"name":"Again"}`, http.StatusConflict, services.ErrBranchExists.Error()},
+		{"already borrowed", http.MethodPost, "/members/1/loans", `{"book_id":102}`, http.StatusConflict, services.ErrBookBorrowed.Error()},
+		{"suspended member", http.MethodPost, "/members/2/loans", `{"book_id":101}`, http.StatusForbidden, services.ErrMemberSuspended.Error()},
+		{"invalid payload", http.MethodPost, "/members/1/loans", `{"book_id":"x"}`, http.StatusBadRequest, "invalid request payload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(t, r, tt.method, tt.path, tt.body)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.status, w.Body)
+			}
+			if want := `{"error":"` + tt.error + `"}`; w.Body.String() != want {
+				t.Errorf("body = %s, want %s", w.Body, want)
+			}
+		})
+	}
+}
+
+func TestBorrowReservedBookConflict(t *testing.T) {
+	r, _, _ := newTestRouter(t)
+	if w := request(t, r, http.MethodPost, "/members/1/reservations", `{"book_id":101}`); w.Code != http.StatusCreated {
+		t.Fatalf("reserve status = %d (body %s)", w.Code, w.Body)
+	}
+
+	w := request(t, r, http.MethodPost, "/members/2/loans", `{"book_id":101}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d (body %s)", w.Code, http.StatusConflict, w.Body)
+	}
+	if want := `{"error":"` + services.ErrBookReserved.Error() + `"}`; w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body, want)
+	}
+}
+
+func TestBatchRejectedBody(t *testing.T) {
+	r, library, _ := newTestRouter(t)
+	if err := library.BorrowBook(102, 2); err != nil {
+		t.Fatalf("BorrowBook: %v", err)
+	}
+
+	w := request(t, r, http.MethodPost, "/members/1/loans/batch", `{"book_ids":[101,102,999]}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d (body %s)", w.Code, http.StatusConflict, w.Body)
+	}
+	want := `{"error":"batch rejected; no books were processed","items":[` +
+		`{"book_id":102,"error":"book is already borrowed"},` +
+		`{"book_id":999,"error":"book not found"}]}`
+	if w.Body.String() != want {
+		t.Errorf("body = %s\nwant %s", w.Body, want)
+	}
+	if book, _ := library.GetBook(101); book.Status != models.StatusAvailable {
+		t.Errorf("book 101 status = %q after rejected batch, want %q", book.Status, models.StatusAvailable)
+	}
+}
+
+func TestDeleteMemberRemovesLogin(t *testing.T) {
+	r, _, accounts := newTestRouter(t)
+	if _, err := accounts.SetMemberPIN(2, "1234"); err != nil {
+		t.Fatalf("SetMemberPIN: %v", err)
+	}
+
+	if w := request(t, r, http.MethodDelete, "/members/2", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d (body %s)", w.Code, http.StatusNoContent, w.Body)
+	}
+	if _, err := accounts.Login(auth.MemberUsername(2), "1234"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Fatalf("Login of a removed member = %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"library_management/auth"
+	"library_management/concurrency"
+	"library_management/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRouter initializes the Gin router and configures the library routes.
+// Removing a member also removes their login from accounts.
+func SetupRouter(library services.LibraryManager, pool *concurrency.CommandPool, accounts *auth.Store) *gin.Engine {
+	r := gin.Default()
+	h := NewHandler(library, pool, accounts)
+
+	books := r.Group("/books")
+	{
+		books.GET("", h.ListBooks)
+		books.GET("/search", h.SearchBooks)
+		books.GET("/:id", h.GetBook)
+		books.GET("/:id/also-borrowed", h.AlsoBorrowed)
+		books.POST("", h.CreateBook)
+		books.DELETE("/:id", h.DeleteBook)
+		books.PUT("/:id/status", h.UpdateBookStatus)
+		books.POST("/:id/transfer", h.TransferBook)
+		books.POST("/:id/receive", h.ReceiveBook)
+	}
+
+	branches := r.Group("/branches")
+	{
+		branches.GET("", h.ListBranches)
+		branches.POST("", h.CreateBranch)
+	}
+
+	members := r.Group("/members")
+	{
+		members.GET("", h.ListMembers)
+		members.GET("/:id", h.GetMember)
+		members.POST("", h.CreateMember)
+		members.PUT("/:id", h.UpdateMember)
+		members.PUT("/:id/suspended", h.SuspendMember)
+		members.DELETE("/:id", h.DeleteMember)
+
+		// Loans and reservations belong to a member.
+		members.GET("/:id/loans", h.ListLoans)
+		members.GET("/:id/recommendations", h.Recommendations)
+		members.POST("/:id/loans", h.BorrowBook)
+		members.POST("/:id/loans/batch", h.BorrowBooks)
+		members.POST("/:id/returns", h.ReturnBooks)
+		members.DELETE("/:id/loans/:bookId", h.ReturnBook)
+		members.POST("/:id/reservations", h.ReserveBook)
+	}
+
+	return r
+}
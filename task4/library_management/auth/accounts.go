@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Role decides what an account may do at the console.
+type Role string
+
+const (
+	// RoleLibrarian manages the catalog and members and may act for any member.
+	RoleLibrarian Role = "librarian"
+	// RoleMember may only borrow, return and reserve books for itself.
+	RoleMember Role = "member"
+)
+
+// Minimum secret lengths.
+const (
+	MinPasswordLength = 8
+	MinPINLength      = 4
+)
+
+// Errors returned by Store. Use errors.Is to match them.
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAccountExists      = errors.New("account already exists")
+	ErrWeakPassword       = errors.New("password must be at least 8 characters")
+	ErrWeakPIN            = errors.New("PIN must be at least 4 digits")
+)
+
+// Account is a console login. Members log in with their member ID as the
+// username and a PIN; librarians with a username and password. Only the
+// bcrypt hash of the secret is kept.
+type Account struct {
+	Username     string `json:"username"`
+	Role         Role   `json:"role"`
+	MemberID     int    `json:"member_id,omitempty"` // Set for member accounts
+	PasswordHash string `json:"password_hash"`
+}
+
+// IsLibrarian reports whether the account has librarian rights.
+func (a Account) IsLibrarian() bool {
+	return a.Role == RoleLibrarian
+}
+
+// CanActFor reports whether the account may borrow, return, reserve or view
+// loans for memberID: librarians for anyone, members only for themselves.
+func (a Account) CanActFor(memberID int) bool {
+	return a.IsLibrarian() || (a.Role == RoleMember && a.MemberID == memberID)
+}
+
+// MemberUsername is the username of a member's account.
+func MemberUsername(memberID int) string {
+	return strconv.Itoa(memberID)
+}
+
+// Store holds the accounts, saving them to a JSON file after every change
+// when it has a path.
+type Store struct {
+	path     string
+	accounts map[string]Account // Keyed by username
+	mu       sync.Mutex         // Protects accounts and the file
+}
+
+// NewStore loads the accounts saved at path. A missing file is an empty
+// store; an empty path keeps accounts in memory only.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, accounts: make(map[string]Account)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var accounts []Account
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, err
+	}
+	for _, a := range accounts {
+		s.accounts[a.Username] = a
+	}
+	return s, nil
+}
+
+// AddLibrarian creates a librarian account.
+func (s *Store) AddLibrarian(username, password string) (Account, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return Account{}, errors.New("username cannot be empty")
+	}
+	if len(password) < MinPasswordLength {
+		return Account{}, ErrWeakPassword
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.accounts[username]; exists {
+		return Account{}, ErrAccountExists
+	}
+	return s.put(Account{Username: username, Role: RoleLibrarian}, password)
+}
+
+// SetMemberPIN creates the account of a member, or replaces its PIN.
+func (s *Store) SetMemberPIN(memberID int, pin string) (Account, error) {
+	if len(pin) < MinPINLength || strings.Trim(pin, "0123456789") != "" {
+		return Account{}, ErrWeakPIN
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	username := MemberUsername(memberID)
+	if a, exists := s.accounts[username]; exists && a.Role != RoleMember {
+		return Account{}, ErrAccountExists
+	}
+	return s.put(Account{Username: username, Role: RoleMember, MemberID: memberID}, pin)
+}
+
+// RemoveMember deletes a member's account, if any.
+func (s *Store) RemoveMember(memberID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	username := MemberUsername(memberID)
+	if a, exists := s.accounts[username]; !exists || a.Role != RoleMember {
+		return nil
+	}
+	delete(s.accounts, username)
+	return s.save()
+}
+
+// Login checks a username and secret and returns the account. Unknown
+// usernames and wrong secrets return the same error.
+func (s *Store) Login(username, secret string) (Account, error) {
+	s.mu.Lock()
+	account, exists := s.accounts[strings.TrimSpace(username)]
+	s.mu.Unlock()
+
+	if !exists {
+		return Account{}, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(secret)); err != nil {
+		return Account{}, ErrInvalidCredentials
+	}
+	return account, nil
+}
+
+// HasLibrarian reports whether any librarian account exists.
+func (s *Store) HasLibrarian() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.accounts {
+		if a.IsLibrarian() {
+			return true
+		}
+	}
+	return false
+}
+
+// put hashes the secret and stores the account. Callers hold s.mu.
+func (s *Store) put(account Account, secret string) (Account, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return Account{}, err
+	}
+	account.PasswordHash = string(hash)
+	previous, existed := s.accounts[account.Username]
+	s.accounts[account.Username] = account
+	if err := s.save(); err != nil {
+		if existed {
+			s.accounts[account.Username] = previous
+		} else {
+			delete(s.accounts, account.Username)
+		}
+		return Account{}, err
+	}
+	return account, nil
+}
+
+// save writes all accounts to the store's file, readable only by its owner.
+// Callers hold s.mu.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	accounts := make([]Account, 0, len(s.accounts))
+	for _, a := range s.accounts {
+		accounts = append(accounts, a)
+	}
+	data, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
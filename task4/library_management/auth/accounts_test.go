@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoginAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if store.HasLibrarian() {
+		t.Fatal("new store has a librarian")
+	}
+	if _, err := store.AddLibrarian("admin", "short"); !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("AddLibrarian with short password = %v, want %v", err, ErrWeakPassword)
+	}
+	if _, err := store.AddLibrarian("admin", "correct horse"); err != nil {
+		t.Fatalf("AddLibrarian: %v", err)
+	}
+	if _, err := store.AddLibrarian("admin", "another password"); !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("duplicate AddLibrarian = %v, want %v", err, ErrAccountExists)
+	}
+	if _, err := store.SetMemberPIN(7, "12ab"); !errors.Is(err, ErrWeakPIN) {
+		t.Fatalf("SetMemberPIN with letters = %v, want %v", err, ErrWeakPIN)
+	}
+	if _, err := store.SetMemberPIN(7, "2468"); err != nil {
+		t.Fatalf("SetMemberPIN: %v", err)
+	}
+
+	// A reloaded store accepts the same credentials.
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !reloaded.HasLibrarian() {
+		t.Fatal("reloaded store lost the librarian")
+	}
+	member, err := reloaded.Login("7", "2468")
+	if err != nil {
+		t.Fatalf("member Login: %v", err)
+	}
+	if member.IsLibrarian() || !member.CanActFor(7) || member.CanActFor(8) {
+		t.Fatalf("member account %+v has the wrong rights", member)
+	}
+	librarian, err := reloaded.Login("admin", "correct horse")
+	if err != nil {
+		t.Fatalf("librarian Login: %v", err)
+	}
+	if !librarian.CanActFor(8) {
+		t.Fatal("librarian cannot act for members")
+	}
+
+	for _, c := range [][2]string{{"admin", "wrong password"}, {"7", "1357"}, {"nobody", "correct horse"}} {
+		if _, err := reloaded.Login(c[0], c[1]); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Login(%q, %q) = %v, want %v", c[0], c[1], err, ErrInvalidCredentials)
+		}
+	}
+
+	if err := reloaded.RemoveMember(7); err != nil {
+		t.Fatalf("RemoveMember: %v", err)
+	}
+	if _, err := reloaded.Login("7", "2468"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login after RemoveMember = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
@@ -0,0 +1,138 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"library_management/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BookExport is an exported book with its loan, if any.
+type BookExport struct {
+	ID         int        `json:"id"`
+	Title      string     `json:"title"`
+	Author     string     `json:"author"`
+	Status     string     `json:"status"`
+	ReservedBy int        `json:"reserved_by,omitempty"`
+	BorrowedBy int        `json:"borrowed_by,omitempty"`
+	BorrowedAt *time.Time `json:"borrowed_at,omitempty"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	HomeBranch int        `json:"home_branch"`
+	Branch     int        `json:"branch"`
+	InTransit  int        `json:"in_transit_to,omitempty"` // Destination branch while In Transit
+}
+
+// MemberExport is an exported member.
+type MemberExport struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Suspended bool   `json:"suspended"`
+	Loans     []int  `json:"loans"` // IDs of the books on loan
+}
+
+// Snapshot is the full catalog and loan state of a library.
+type Snapshot struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	Books      []BookExport   `json:"books"`
+	Members    []MemberExport `json:"members"`
+}
+
+// NewSnapshot builds a Snapshot from the library's books and members,
+// ordered by ID.
+func NewSnapshot(books []models.Book, members []models.Member) Snapshot {
+	loans := make(map[int]models.Loan)
+	snap := Snapshot{
+		ExportedAt: time.Now(),
+		Books:      make([]BookExport, 0, len(books)),
+		Members:    make([]MemberExport, 0, len(members)),
+	}
+
+	for _, m := range members {
+		ids := make([]int, 0, len(m.Loans))
+		for _, loan := range m.Loans {
+			loans[loan.BookID] = loan
+			ids = append(ids, loan.BookID)
+		}
+		snap.Members = append(snap.Members, MemberExport{ID: m.ID, Name: m.Name, Suspended: m.Suspended, Loans: ids})
+	}
+	for _, b := range books {
+		exp := BookExport{
+			ID: b.ID, Title: b.Title, Author: b.Author, Status: string(b.Status), ReservedBy: b.ReservedBy,
+			HomeBranch: b.HomeBranch, Branch: b.Branch, InTransit: b.Destination,
+		}
+		if loan, ok := loans[b.ID]; ok {
+			borrowedAt, due := loan.BorrowedAt, loan.DueDate
+			exp.BorrowedBy, exp.BorrowedAt, exp.DueDate = loan.MemberID, &borrowedAt, &due
+		}
+		snap.Books = append(snap.Books, exp)
+	}
+
+	sort.Slice(snap.Books, func(i, j int) bool { return snap.Books[i].ID < snap.Books[j].ID })
+	sort.Slice(snap.Members, func(i, j int) bool { return snap.Members[i].ID < snap.Members[j].ID })
+	return snap
+}
+
+// Export writes the snapshot to w. JSON output contains books and members;
+// CSV output has one row per book including its loan columns, since a CSV
+// file holds a single table. ExportMembersCSV writes the members table.
+func Export(w io.Writer, format Format, snap Snapshot) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snap)
+
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"id", "title", "author", "status", "reserved_by", "borrowed_by", "borrowed_at", "due_date", "home_branch", "branch", "in_transit_to"})
+		for _, b := range snap.Books {
+			cw.Write([]string{
+				strconv.Itoa(b.ID), b.Title, b.Author, b.Status,
+				optionalID(b.ReservedBy), optionalID(b.BorrowedBy),
+				optionalTime(b.BorrowedAt), optionalTime(b.DueDate),
+				strconv.Itoa(b.HomeBranch), strconv.Itoa(b.Branch), optionalID(b.InTransit),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// ExportMembersCSV writes the snapshot's members to w as CSV, one row per
+// member with the IDs of their loaned books separated by spaces. The output
+// can be imported with ImportMembers.
+func ExportMembersCSV(w io.Writer, snap Snapshot) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "name", "suspended", "loans"})
+	for _, m := range snap.Members {
+		loans := make([]string, len(m.Loans))
+		for i, id := range m.Loans {
+			loans[i] = strconv.Itoa(id)
+		}
+		cw.Write([]string{strconv.Itoa(m.ID), m.Name, strconv.FormatBool(m.Suspended), strings.Join(loans, " ")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func optionalID(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
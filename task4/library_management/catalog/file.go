@@ -0,0 +1,74 @@
+package catalog
+
+import (
+	"io"
+	"library_management/models"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportBooksFile imports books from a .csv or .json file; see ImportBooks.
+func ImportBooksFile(path string, exists func(id int) bool) ([]models.Book, []RowError, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	return ImportBooks(file, format, exists)
+}
+
+// ImportMembersFile imports members from a .csv or .json file; see ImportMembers.
+func ImportMembersFile(path string, exists func(id int) bool) ([]models.Member, []RowError, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	return ImportMembers(file, format, exists)
+}
+
+// ExportFile writes the snapshot to a .csv or .json file, replacing it. A
+// CSV export holds only the books, so the members of the snapshot, if any,
+// are written next to it to MembersPath(path).
+func ExportFile(path string, snap Snapshot) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(path, func(w io.Writer) error { return Export(w, format, snap) }); err != nil {
+		return err
+	}
+	if format != FormatCSV || len(snap.Members) == 0 {
+		return nil
+	}
+	return writeFile(MembersPath(path), func(w io.Writer) error { return ExportMembersCSV(w, snap) })
+}
+
+// MembersPath is the file a CSV export at path writes its members to:
+// catalog.csv becomes catalog.members.csv.
+func MembersPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".members" + ext
+}
+
+// writeFile creates or replaces the file at path with the output of write.
+func writeFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
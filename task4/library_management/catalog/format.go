@@ -0,0 +1,54 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a file format supported for import and export.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// Errors reported by import and export. Row errors wrap one of them.
+var (
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	ErrInvalidID         = errors.New("id must be a positive integer")
+	ErrInvalidBranch     = errors.New("home_branch must be a positive integer")
+	ErrInvalidRow        = errors.New("invalid row")
+	ErrMissingField      = errors.New("required field is missing")
+	ErrDuplicateID       = errors.New("duplicate id")
+	ErrRowsRejected      = errors.New("rows were rejected")
+)
+
+// FormatFromPath picks the format from a file extension (.csv or .json).
+func FormatFromPath(path string) (Format, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, ext)
+	}
+}
+
+// RowError reports why a single imported row was rejected.
+// Row is 1-based and counts data rows, not the CSV header.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,251 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"library_management/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bookRecord is the file representation of an imported book.
+type bookRecord struct {
+	ID         int    `json:"id"`
+	Title      string `json:"title"`
+	Author     string `json:"author"`
+	HomeBranch int    `json:"home_branch"`
+}
+
+// memberRecord is the file representation of an imported member.
+type memberRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// numbered pairs a decoded record with its 1-based row number.
+type numbered[T any] struct {
+	row int
+	rec T
+}
+
+// ImportBooks reads books from r. CSV input needs a header row with the
+// columns id, title and author (in any order) and may have a home_branch
+// column; JSON input is an array of {"id", "title", "author", "home_branch"}
+// objects or an exported Snapshot, whose books are imported. Other columns
+// and fields, such as the loan state of an export, are ignored. Rows with an
+// invalid ID or home branch, a missing title, an ID repeated in the file, or
+// an ID for which exists reports true are rejected with a RowError; the
+// remaining books are returned in file order. The error is only set when the
+// input as a whole cannot be read.
+func ImportBooks(r io.Reader, format Format, exists func(id int) bool) ([]models.Book, []RowError, error) {
+	columns := []string{"id", "title", "author", "home_branch"}
+	records, errs, err := decode(r, format, "books", columns, 3, func(fields map[string]string) (bookRecord, error) {
+		id, err := parseID(fields["id"])
+		if err != nil {
+			return bookRecord{}, err
+		}
+		branch, err := parseBranch(fields["home_branch"])
+		return bookRecord{ID: id, Title: fields["title"], Author: fields["author"], HomeBranch: branch}, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	books := []models.Book{}
+	seen := make(map[int]bool)
+	for _, r := range records {
+		if r.rec.HomeBranch < 0 {
+			errs = append(errs, RowError{Row: r.row, Err: ErrInvalidBranch})
+			continue
+		}
+		if err := validate(r.rec.ID, r.rec.Title, "title", seen, exists); err != nil {
+			errs = append(errs, RowError{Row: r.row, Err: err})
+			continue
+		}
+		books = append(books, models.Book{
+			ID:         r.rec.ID,
+			Title:      strings.TrimSpace(r.rec.Title),
+			Author:     strings.TrimSpace(r.rec.Author),
+			HomeBranch: r.rec.HomeBranch,
+		})
+	}
+	sortRowErrors(errs)
+	return books, errs, nil
+}
+
+// ImportMembers reads members from r. CSV input needs a header row with the
+// columns id and name; JSON input is an array of {"id", "name"} objects or
+// an exported Snapshot, whose members are imported without their loans.
+// Rows are validated like ImportBooks, with name as the required field.
+func ImportMembers(r io.Reader, format Format, exists func(id int) bool) ([]models.Member, []RowError, error) {
+	records, errs, err := decode(r, format, "members", []string{"id", "name"}, 2, func(fields map[string]string) (memberRecord, error) {
+		id, err := parseID(fields["id"])
+		return memberRecord{ID: id, Name: fields["name"]}, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	members := []models.Member{}
+	seen := make(map[int]bool)
+	for _, r := range records {
+		if err := validate(r.rec.ID, r.rec.Name, "name", seen, exists); err != nil {
+			errs = append(errs, RowError{Row: r.row, Err: err})
+			continue
+		}
+		members = append(members, models.Member{
+			ID:    r.rec.ID,
+			Name:  strings.TrimSpace(r.rec.Name),
+			Loans: []models.Loan{},
+		})
+	}
+	sortRowErrors(errs)
+	return members, errs, nil
+}
+
+// decode reads records in the given format. JSON input is an array of
+// records or an object holding the array under key, as in a Snapshot. For
+// CSV, columns are matched by header name; the first required of them must
+// be present. Each row is decoded on its own, so a row that fails to decode
+// or convert is reported as a RowError without rejecting the rest.
+func decode[T any](r io.Reader, format Format, key string, columns []string, required int, fromCSV func(map[string]string) (T, error)) ([]numbered[T], []RowError, error) {
+	switch format {
+	case FormatJSON:
+		rows, err := jsonRows(r, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		var out []numbered[T]
+		var errs []RowError
+		for i, raw := range rows {
+			var rec T
+			if err := json.Unmarshal(raw, &rec); err != nil {
+				errs = append(errs, RowError{Row: i + 1, Err: fmt.Errorf("%w: %v", ErrInvalidRow, err)})
+				continue
+			}
+			out = append(out, numbered[T]{row: i + 1, rec: rec})
+		}
+		return out, errs, nil
+
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		header, err := reader.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read csv header: %w", err)
+		}
+		index := make(map[string]int)
+		for i, name := range header {
+			index[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		for _, col := range columns[:required] {
+			if _, ok := index[col]; !ok {
+				return nil, nil, fmt.Errorf("csv header: %w: %q", ErrMissingField, col)
+			}
+		}
+
+		var out []numbered[T]
+		var errs []RowError
+		for row := 1; ; row++ {
+			values, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("read csv: %w", err)
+			}
+			fields := make(map[string]string, len(columns))
+			for _, col := range columns {
+				if i, ok := index[col]; ok && i < len(values) {
+					fields[col] = values[i]
+				}
+			}
+			rec, err := fromCSV(fields)
+			if err != nil {
+				errs = append(errs, RowError{Row: row, Err: err})
+				continue
+			}
+			out = append(out, numbered[T]{row: row, rec: rec})
+		}
+		return out, errs, nil
+
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// jsonRows splits JSON input into its rows: the elements of a top-level
+// array, or of the array under key in a top-level object.
+func jsonRows(r io.Reader, key string) ([]json.RawMessage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read json: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("decode json: %w", err)
+		}
+		array, ok := doc[key]
+		if !ok {
+			return nil, fmt.Errorf("decode json: %w: %q", ErrMissingField, key)
+		}
+		data = array
+	}
+	var rows []json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	return rows, nil
+}
+
+// validate checks the fields shared by every imported row and records the ID
+// as seen when it is valid.
+func validate(id int, required string, requiredName string, seen map[int]bool, exists func(id int) bool) error {
+	switch {
+	case id <= 0:
+		return ErrInvalidID
+	case strings.TrimSpace(required) == "":
+		return fmt.Errorf("%w: %s", ErrMissingField, requiredName)
+	case seen[id]:
+		return fmt.Errorf("%w %d in file", ErrDuplicateID, id)
+	case exists != nil && exists(id):
+		return fmt.Errorf("%w %d already in library", ErrDuplicateID, id)
+	}
+	seen[id] = true
+	return nil
+}
+
+// parseID converts a CSV id column.
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidID
+	}
+	return id, nil
+}
+
+// parseBranch converts an optional CSV home_branch column; empty means the
+// default branch.
+func parseBranch(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidBranch
+	}
+	return id, nil
+}
+
+// sortRowErrors orders row errors by row number.
+func sortRowErrors(errs []RowError) {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+}
@@ -0,0 +1,201 @@
+package catalog
+
+import (
+	"bytes"
+	"errors"
+	"library_management/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkRowErrors verifies that exactly the rows in want were rejected, each
+// with an error matching want[row].
+func checkRowErrors(t *testing.T, got []RowError, want map[int]error) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("row errors = %v, want rows %v", got, want)
+	}
+	for _, e := range got {
+		if !errors.Is(e, want[e.Row]) {
+			t.Errorf("row %d: %v, want %v", e.Row, e.Err, want[e.Row])
+		}
+	}
+}
+
+func TestImportBooks(t *testing.T) {
+	exists := func(id int) bool { return id == 101 }
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+		books  []models.Book
+		errs   map[int]error
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			input: "Author, ID, Title, home_branch\n" +
+				"Kernighan, 1, The C Programming Language,\n" +
+				"Pike, 2, The Practice of Programming, 2\n" +
+				"Nobody, x, Bad ID,\n" +
+				"Nobody, 3, ,\n" +
+				"Again, 1, Duplicate,\n" +
+				"Exists, 101, In Library,\n" +
+				"Nobody, 4, Bad Branch, east\n",
+			books: []models.Book{
+				{ID: 1, Title: "The C Programming Language", Author: "Kernighan"},
+				{ID: 2, Title: "The Practice of Programming", Author: "Pike", HomeBranch: 2},
+			},
+			errs: map[int]error{3: ErrInvalidID, 4: ErrMissingField, 5: ErrDuplicateID, 6: ErrDuplicateID, 7: ErrInvalidBranch},
+		},
+		{
+			name:   "csv without home branch column",
+			format: FormatCSV,
+			input:  "id,title,author\n1,Go,Pike\n",
+			books:  []models.Book{{ID: 1, Title: "Go", Author: "Pike"}},
+		},
+		{
+			name:   "json array",
+			format: FormatJSON,
+			input: `[
+				{"id": 1, "title": " Go ", "author": "Pike", "home_branch": 2},
+				{"id": "2", "title": "Wrong type"},
+				{"id": 3, "title": "Negative branch", "home_branch": -1},
+				{"id": 0, "title": "Zero"},
+				{"id": 4, "title": "Unknown fields are ignored", "isbn": "x"}
+			]`,
+			books: []models.Book{
+				{ID: 1, Title: "Go", Author: "Pike", HomeBranch: 2},
+				{ID: 4, Title: "Unknown fields are ignored"},
+			},
+			errs: map[int]error{2: ErrInvalidRow, 3: ErrInvalidBranch, 4: ErrInvalidID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books, errs, err := ImportBooks(strings.NewReader(tt.input), tt.format, exists)
+			if err != nil {
+				t.Fatalf("ImportBooks: %v", err)
+			}
+			if !reflect.DeepEqual(books, tt.books) {
+				t.Errorf("books = %+v, want %+v", books, tt.books)
+			}
+			checkRowErrors(t, errs, tt.errs)
+		})
+	}
+}
+
+func TestImportRejectsUnreadableInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+	}{
+		{"csv missing column", FormatCSV, "id,author\n1,Pike\n"},
+		{"json not an array", FormatJSON, `"books"`},
+		{"json object without books", FormatJSON, `{"members": []}`},
+		{"json syntax", FormatJSON, `[{"id": 1,`},
+		{"unsupported format", Format("xml"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ImportBooks(strings.NewReader(tt.input), tt.format, nil); err == nil {
+				t.Fatal("ImportBooks succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestImportMembers(t *testing.T) {
+	members, errs, err := ImportMembers(strings.NewReader("id,name\n1,Alice\n2,\n1,Again\n"), FormatCSV, nil)
+	if err != nil {
+		t.Fatalf("ImportMembers: %v", err)
+	}
+	want := []models.Member{{ID: 1, Name: "Alice", Loans: []models.Loan{}}}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("members = %+v, want %+v", members, want)
+	}
+	checkRowErrors(t, errs, map[int]error{2: ErrMissingField, 3: ErrDuplicateID})
+}
+
+func TestExportCanBeImported(t *testing.T) {
+	borrowedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	books := []models.Book{
+		{ID: 1, Title: "Go", Author: "Pike", Status: models.StatusBorrowed, HomeBranch: 1, Branch: 1},
+		{ID: 2, Title: "C", Author: "Kernighan", Status: models.StatusInTransit, HomeBranch: 2, Branch: 1, Destination: 2},
+	}
+	members := []models.Member{
+		{ID: 7, Name: "Alice", Loans: []models.Loan{{BookID: 1, MemberID: 7, BorrowedAt: borrowedAt, DueDate: borrowedAt.Add(time.Hour)}}},
+	}
+	snap := NewSnapshot(books, members)
+
+	wantBooks := []models.Book{
+		{ID: 1, Title: "Go", Author: "Pike", HomeBranch: 1},
+		{ID: 2, Title: "C", Author: "Kernighan", HomeBranch: 2},
+	}
+	for _, format := range []Format{FormatJSON, FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, format, snap); err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			got, errs, err := ImportBooks(bytes.NewReader(buf.Bytes()), format, nil)
+			if err != nil || len(errs) != 0 {
+				t.Fatalf("ImportBooks of an export: %v %v", err, errs)
+			}
+			if !reflect.DeepEqual(got, wantBooks) {
+				t.Errorf("books = %+v, want %+v", got, wantBooks)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, FormatJSON, snap); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	got, errs, err := ImportMembers(&buf, FormatJSON, nil)
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("ImportMembers of an export: %v %v", err, errs)
+	}
+	if want := []models.Member{{ID: 7, Name: "Alice", Loans: []models.Loan{}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("members = %+v, want %+v", got, want)
+	}
+}
+
+func TestExportFileWritesCSVMembers(t *testing.T) {
+	borrowedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	snap := NewSnapshot(
+		[]models.Book{{ID: 1, Title: "Go", Author: "Pike", Status: models.StatusBorrowed}},
+		[]models.Member{
+			{ID: 7, Name: "Alice", Loans: []models.Loan{{BookID: 1, MemberID: 7, BorrowedAt: borrowedAt, DueDate: borrowedAt}}},
+			{ID: 8, Name: "Bob", Suspended: true},
+		},
+	)
+	path := filepath.Join(t.TempDir(), "catalog.csv")
+	if err := ExportFile(path, snap); err != nil {
+		t.Fatalf("ExportFile: %v", err)
+	}
+	if got := MembersPath(path); got != filepath.Join(filepath.Dir(path), "catalog.members.csv") {
+		t.Fatalf("MembersPath = %q", got)
+	}
+
+	data, err := os.ReadFile(MembersPath(path))
+	if err != nil {
+		t.Fatalf("members file: %v", err)
+	}
+	want := "id,name,suspended,loans\n7,Alice,false,1\n8,Bob,true,\n"
+	if string(data) != want {
+		t.Errorf("members file = %q, want %q", data, want)
+	}
+	members, errs, err := ImportMembersFile(MembersPath(path), nil)
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("ImportMembersFile of the export: %v %v", err, errs)
+	}
+	if len(members) != 2 || members[0].Name != "Alice" || members[1].Name != "Bob" {
+		t.Errorf("members = %+v", members)
+	}
+}
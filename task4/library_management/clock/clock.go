@@ -0,0 +1,124 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and schedules callbacks. The library uses it for
+// reservation holds, due dates and event timestamps so tests can control time.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f once d has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a callback scheduled by AfterFunc.
+type Timer interface {
+	// Stop prevents the callback from running. It reports false if the
+	// callback already ran or the timer was already stopped.
+	Stop() bool
+}
+
+// Real is the wall clock.
+type Real struct{}
+
+// Now returns the current time.
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc wraps time.AfterFunc; f runs in its own goroutine.
+func (Real) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// Fake is a clock that only moves when Advance is called. Callbacks that
+// fall due run synchronously inside Advance, so once Advance returns their
+// effects are visible.
+type Fake struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFake returns a fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// Now returns the fake time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// AfterFunc schedules fn to run when the fake time reaches Now()+d.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{clock: f, when: f.now.Add(d), fn: fn}
+	f.timers = append(f.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, running every callback that falls
+// due in the order of their deadlines. Callbacks may schedule new timers;
+// those run too if they fall due within d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	for {
+		t := f.next(target)
+		if t == nil {
+			break
+		}
+		f.now = t.when
+		f.mu.Unlock()
+		t.fn()
+		f.mu.Lock()
+	}
+	f.now = target
+	f.mu.Unlock()
+}
+
+// Pending reports how many callbacks are scheduled and not yet run.
+func (f *Fake) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// next removes and returns the earliest timer due by target. Callers hold f.mu.
+func (f *Fake) next(target time.Time) *fakeTimer {
+	if len(f.timers) == 0 {
+		return nil
+	}
+	sort.SliceStable(f.timers, func(i, j int) bool { return f.timers[i].when.Before(f.timers[j].when) })
+	t := f.timers[0]
+	if t.when.After(target) {
+		return nil
+	}
+	f.timers = f.timers[1:]
+	return t
+}
+
+type fakeTimer struct {
+	clock *Fake
+	when  time.Time
+	fn    func()
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, other := range t.clock.timers {
+		if other == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"library_management/models"
+	"library_management/services"
+)
+
+// CommandKind identifies the library mutation a Command performs.
+type CommandKind string
+
+const (
+	CommandAddBook      CommandKind = "add_book"
+	CommandRemoveBook   CommandKind = "remove_book"
+	CommandBorrow       CommandKind = "borrow"
+	CommandReturn       CommandKind = "return"
+	CommandReserve      CommandKind = "reserve"
+	CommandUpdateStatus CommandKind = "update_status"
+	CommandTransfer     CommandKind = "transfer"
+	CommandReceive      CommandKind = "receive"
+	CommandBorrowBatch  CommandKind = "borrow_batch"
+	CommandReturnBatch  CommandKind = "return_batch"
+	CommandAddMember    CommandKind = "add_member"
+	CommandUpdateMember CommandKind = "update_member"
+	CommandSuspend      CommandKind = "suspend_member"
+	CommandRemoveMember CommandKind = "remove_member"
+	CommandAddBranch    CommandKind = "add_branch"
+)
+
+// Command encapsulates a single library mutation.
+// Book is only used by CommandAddBook; BookID is taken from Book.ID for it.
+// Member is only used by CommandAddMember; MemberID is taken from Member.ID
+// for it. Name is the new name of CommandUpdateMember and the name of
+// CommandAddBranch, whose branch ID is Branch. Suspended is only used by
+// CommandSuspend, which also reinstates members.
+// Status is only used by CommandUpdateStatus.
+// BookIDs is only used by the batch commands, which apply to all of the
+// books or none; BookID is ignored for them.
+// Branch is where a borrow or return happens, the pickup branch of a
+// reservation, or the destination of a transfer or receive; 0 means the
+// book's current branch for borrows, returns and reservations.
+// Ctx is optional; when it is done before a worker picks the command up, the
+// command is skipped and Response receives ErrRequestTimeout or
+// ErrRequestCanceled. Response should be buffered so workers never block on
+// callers that gave up waiting.
+type Command struct {
+	Ctx       context.Context
+	Kind      CommandKind
+	BookID    int
+	BookIDs   []int
+	MemberID  int
+	Book      models.Book
+	Member    models.Member
+	Name      string
+	Suspended bool
+	Status    models.BookStatus
+	Branch    int
+	Response  chan error
+}
+
+// Observer is called after every executed command with its result.
+// Observers run on the worker goroutine and must not block for long.
+type Observer func(cmd Command, err error)
+
+// context returns the command's context, defaulting to context.Background.
+func (c Command) context() context.Context {
+	if c.Ctx == nil {
+		return context.Background()
+	}
+	return c.Ctx
+}
+
+// bookID is the book the command is about: the first one for batches.
+func (c Command) bookID() int {
+	switch {
+	case c.Kind == CommandAddBook:
+		return c.Book.ID
+	case len(c.BookIDs) > 0:
+		return c.BookIDs[0]
+	}
+	return c.BookID
+}
+
+// memberID is the member the command is about.
+func (c Command) memberID() int {
+	if c.Kind == CommandAddMember {
+		return c.Member.ID
+	}
+	return c.MemberID
+}
+
+// books lists every book the command touches, used to keep per-book ordering.
+// Member and branch commands have no book and share the shard of book 0.
+func (c Command) books() []int {
+	if len(c.BookIDs) > 0 {
+		return c.BookIDs
+	}
+	return []int{c.bookID()}
+}
+
+// Execute applies the command to the library.
+func (c Command) Execute(library services.LibraryManager) error {
+	switch c.Kind {
+	case CommandAddBook:
+		return library.AddBook(c.Book)
+	case CommandRemoveBook:
+		return library.RemoveBook(c.BookID)
+	case CommandBorrow:
+		return library.BorrowBookAt(c.BookID, c.MemberID, c.Branch)
+	case CommandReturn:
+		return library.ReturnBookAt(c.BookID, c.MemberID, c.Branch)
+	case CommandReserve:
+		return library.ReserveBookAt(c.BookID, c.MemberID, c.Branch)
+	case CommandUpdateStatus:
+		return library.UpdateBookStatus(c.BookID, c.Status)
+	case CommandTransfer:
+		return library.TransferBook(c.BookID, c.Branch)
+	case CommandReceive:
+		return library.ReceiveBook(c.BookID, c.Branch)
+	case CommandBorrowBatch:
+		return library.BorrowBooks(c.BookIDs, c.MemberID, c.Branch)
+	case CommandReturnBatch:
+		return library.ReturnBooks(c.BookIDs, c.MemberID, c.Branch)
+	case CommandAddMember:
+		return library.AddMember(c.Member)
+	case CommandUpdateMember:
+		return library.UpdateMember(c.MemberID, c.Name)
+	case CommandSuspend:
+		return library.SetMemberSuspended(c.MemberID, c.Suspended)
+	case CommandRemoveMember:
+		return library.RemoveMember(c.MemberID)
+	case CommandAddBranch:
+		return library.AddBranch(models.Branch{ID: c.Branch, Name: c.Name})
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownCommand, c.Kind)
+	}
+}
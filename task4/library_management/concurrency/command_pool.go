@@ -0,0 +1,313 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library_management/models"
+	"library_management/services"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Errors reported instead of a library result when a command could not be
+// processed. They let callers tell a timeout apart from business errors such
+// as services.ErrBookNotAvailable.
+var (
+	ErrPoolClosed      = errors.New("command pool is shut down")
+	ErrRequestTimeout  = errors.New("request timed out")
+	ErrRequestCanceled = errors.New("request canceled")
+	ErrUnknownCommand  = errors.New("unknown command")
+)
+
+// contextError converts a finished context into ErrRequestTimeout or
+// ErrRequestCanceled, keeping the original context error wrapped.
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrRequestTimeout, ctx.Err())
+	}
+	return fmt.Errorf("%w: %w", ErrRequestCanceled, ctx.Err())
+}
+
+// CommandPool processes library commands on a fixed number of worker
+// goroutines. Commands are sharded by book ID, so commands for the same book
+// are always handled in the order they were submitted. A batch whose books
+// live on several shards is queued on each of them and runs once all of
+// those workers have reached it, so it is ordered with every command for
+// each of its books.
+type CommandPool struct {
+	library   services.LibraryManager
+	shards    []chan queued // One queue per worker
+	observers []Observer
+	wg        sync.WaitGroup // Tracks running workers
+	depth     atomic.Int64   // Commands queued but not yet picked up
+	metrics   *poolMetrics
+	closed    bool
+	mu        sync.RWMutex  // Protects closed and sends on shards
+	quit      chan struct{} // Closed when Shutdown starts, releasing blocked submitters
+	quitOnce  sync.Once
+	spanMu    sync.Mutex // Queues multi-shard commands one at a time
+}
+
+// queued is a command on a shard. A command spanning several shards has one
+// entry per shard sharing a barrier; the leader runs it once every shard
+// has reached its entry, and the other workers wait until it has finished.
+type queued struct {
+	cmd     Command
+	barrier *barrier
+	leader  bool
+}
+
+// barrier lines up the workers of the shards a command spans.
+type barrier struct {
+	arrived sync.WaitGroup // Done by each worker reaching the command
+	done    chan struct{}  // Closed once the leader has run the command
+}
+
+// NewCommandPool starts a pool of workers, each with a queue holding up to
+// queueSize pending commands. Values below 1 are treated as 1 worker and an
+// unbuffered queue respectively. Observers are called after every command.
+func NewCommandPool(library services.LibraryManager, workers int, queueSize int, observers ...Observer) *CommandPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &CommandPool{
+		library:   library,
+		shards:    make([]chan queued, workers),
+		observers: observers,
+		metrics:   newPoolMetrics(),
+		quit:      make(chan struct{}),
+	}
+	for i := range p.shards {
+		p.shards[i] = make(chan queued, queueSize)
+		p.wg.Add(1)
+		go p.work(p.shards[i])
+	}
+	fmt.Printf("Command pool started with %d workers...\n", workers)
+	return p
+}
+
+// Submit queues a command on the worker responsible for its book, or on
+// each worker responsible for one of a batch's books. It blocks while a
+// queue is full, giving up when the command's context is done or the pool
+// starts shutting down, and fails once the pool is shut down.
+func (p *CommandPool) Submit(cmd Command) error {
+	select {
+	case <-p.quit:
+		return ErrPoolClosed
+	default:
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	shards := p.shardsFor(cmd.books())
+	if len(shards) > 1 {
+		return p.submitSpanning(cmd, shards)
+	}
+
+	ctx := cmd.context()
+	p.depth.Add(1)
+	select {
+	case p.shards[shards[0]] <- queued{cmd: cmd}:
+		return nil
+	case <-ctx.Done():
+		p.depth.Add(-1)
+		return contextError(ctx)
+	case <-p.quit:
+		p.depth.Add(-1)
+		return ErrPoolClosed
+	}
+}
+
+// submitSpanning queues a command on several shards. spanMu keeps spanning
+// commands in the same relative order on every shard; otherwise two of them
+// could each hold one worker while waiting for the other. Callers hold
+// p.mu for reading.
+func (p *CommandPool) submitSpanning(cmd Command, shards []int) error {
+	p.spanMu.Lock()
+	defer p.spanMu.Unlock()
+
+	b := &barrier{done: make(chan struct{})}
+	b.arrived.Add(len(shards))
+	ctx := cmd.context()
+	p.depth.Add(1)
+	select {
+	case p.shards[shards[0]] <- queued{cmd: cmd, barrier: b, leader: true}:
+	case <-ctx.Done():
+		p.depth.Add(-1)
+		return contextError(ctx)
+	case <-p.quit:
+		p.depth.Add(-1)
+		return ErrPoolClosed
+	}
+	// Once the leader is queued the other entries must follow, or its
+	// worker would wait for them forever. Workers keep draining, so these
+	// sends only wait for queue space.
+	for _, shard := range shards[1:] {
+		p.shards[shard] <- queued{cmd: cmd, barrier: b}
+	}
+	return nil
+}
+
+// Do submits a command and waits for its result or for ctx to end.
+// A command that times out while queued is skipped by the worker; one that
+// times out while being processed may still complete in the background.
+func (p *CommandPool) Do(ctx context.Context, cmd Command) error {
+	cmd.Ctx = ctx
+	cmd.Response = make(chan error, 1)
+	if err := p.Submit(cmd); err != nil {
+		return err
+	}
+	select {
+	case err := <-cmd.Response:
+		return err
+	case <-ctx.Done():
+		return contextError(ctx)
+	}
+}
+
+// AddBook adds a book through the pool.
+func (p *CommandPool) AddBook(ctx context.Context, book models.Book) error {
+	return p.Do(ctx, Command{Kind: CommandAddBook, Book: book})
+}
+
+// RemoveBook removes a book through the pool.
+func (p *CommandPool) RemoveBook(ctx context.Context, bookID int) error {
+	return p.Do(ctx, Command{Kind: CommandRemoveBook, BookID: bookID})
+}
+
+// Borrow borrows a book through the pool.
+func (p *CommandPool) Borrow(ctx context.Context, bookID int, memberID int) error {
+	return p.Do(ctx, Command{Kind: CommandBorrow, BookID: bookID, MemberID: memberID})
+}
+
+// Return returns a book through the pool.
+func (p *CommandPool) Return(ctx context.Context, bookID int, memberID int) error {
+	return p.Do(ctx, Command{Kind: CommandReturn, BookID: bookID, MemberID: memberID})
+}
+
+// Reserve reserves a book through the pool.
+func (p *CommandPool) Reserve(ctx context.Context, bookID int, memberID int) error {
+	return p.Do(ctx, Command{Kind: CommandReserve, BookID: bookID, MemberID: memberID})
+}
+
+// QueueDepth reports how many commands are waiting for a worker.
+func (p *CommandPool) QueueDepth() int {
+	return int(p.depth.Load())
+}
+
+// Workers reports the number of worker goroutines.
+func (p *CommandPool) Workers() int {
+	return len(p.shards)
+}
+
+// Shutdown stops accepting commands and waits for the workers to drain the
+// commands already queued. Submitters blocked on a full queue give up with
+// ErrPoolClosed. If ctx ends first, Shutdown returns its error and the
+// workers keep draining in the background.
+func (p *CommandPool) Shutdown(ctx context.Context) error {
+	p.quitOnce.Do(func() { close(p.quit) })
+
+	done := make(chan struct{})
+	go func() {
+		// The write lock waits for submitters still sending, such as the
+		// remaining entries of a spanning command, so it is taken here
+		// rather than before honouring ctx.
+		p.mu.Lock()
+		if !p.closed {
+			p.closed = true
+			for _, shard := range p.shards {
+				close(shard)
+			}
+		}
+		p.mu.Unlock()
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work processes one shard until it is closed and empty.
+func (p *CommandPool) work(commands chan queued) {
+	defer p.wg.Done()
+	for q := range commands {
+		if q.barrier == nil {
+			p.depth.Add(-1)
+			p.run(q.cmd)
+			continue
+		}
+
+		q.barrier.arrived.Done()
+		if !q.leader {
+			<-q.barrier.done
+			continue
+		}
+		p.depth.Add(-1)
+		q.barrier.arrived.Wait()
+		p.run(q.cmd)
+		close(q.barrier.done)
+	}
+}
+
+// run executes a command, or skips it if its context is already done, and
+// reports the result to the observers and the submitter.
+func (p *CommandPool) run(cmd Command) {
+	if ctx := cmd.context(); ctx.Err() != nil {
+		p.metrics.expired.Inc()
+		cmd.respond(contextError(ctx))
+		return
+	}
+	start := time.Now()
+	err := cmd.Execute(p.library)
+	p.metrics.observe(cmd, err, time.Since(start))
+	for _, observe := range p.observers {
+		observe(cmd, err)
+	}
+	cmd.respond(err)
+}
+
+// respond delivers the result if the submitter asked for one.
+func (c Command) respond(err error) {
+	if c.Response != nil {
+		c.Response <- err
+	}
+}
+
+// shardsFor returns the distinct workers responsible for the books, in
+// ascending order.
+func (p *CommandPool) shardsFor(bookIDs []int) []int {
+	seen := make(map[int]bool, len(bookIDs))
+	var shards []int
+	for _, id := range bookIDs {
+		shard := p.shardFor(id)
+		if !seen[shard] {
+			seen[shard] = true
+			shards = append(shards, shard)
+		}
+	}
+	sort.Ints(shards)
+	return shards
+}
+
+// shardFor maps a book ID to a worker index.
+func (p *CommandPool) shardFor(bookID int) int {
+	shard := bookID % len(p.shards)
+	if shard < 0 {
+		shard = -shard
+	}
+	return shard
+}
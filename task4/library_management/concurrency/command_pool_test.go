@@ -0,0 +1,331 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"library_management/models"
+	"library_management/services"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestLibrary returns a library with members 1 and 2 and books 1 to n.
+func newTestLibrary(t *testing.T, n int) *services.Library {
+	t.Helper()
+	library := services.NewLibrary()
+	t.Cleanup(library.Close)
+	library.AddMember(models.Member{ID: 1, Name: "Alice"})
+	library.AddMember(models.Member{ID: 2, Name: "Bob"})
+	for id := 1; id <= n; id++ {
+		library.AddBook(models.Book{ID: id, Title: "Book"})
+	}
+	return library
+}
+
+// blockingLibrary holds every UpdateBookStatus call until release is
+// closed, so tests can keep a worker busy.
+type blockingLibrary struct {
+	services.LibraryManager
+	started chan int // Receives the book ID of each blocked call
+	release chan struct{}
+}
+
+func newBlockingLibrary(library services.LibraryManager) *blockingLibrary {
+	return &blockingLibrary{LibraryManager: library, started: make(chan int, 16), release: make(chan struct{})}
+}
+
+func (b *blockingLibrary) UpdateBookStatus(bookID int, status models.BookStatus) error {
+	b.started <- bookID
+	<-b.release
+	return b.LibraryManager.UpdateBookStatus(bookID, status)
+}
+
+// block occupies the worker of bookID, marking the book Damaged once
+// b.release is closed.
+func (b *blockingLibrary) block(t *testing.T, pool *CommandPool, bookID int) {
+	t.Helper()
+	if err := pool.Submit(Command{Kind: CommandUpdateStatus, BookID: bookID, Status: models.StatusDamaged}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	select {
+	case <-b.started:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not pick up the blocking command")
+	}
+}
+
+// submit queues cmd and returns the channel its result arrives on.
+func submit(t *testing.T, pool *CommandPool, cmd Command) chan error {
+	t.Helper()
+	cmd.Response = make(chan error, 1)
+	if err := pool.Submit(cmd); err != nil {
+		t.Fatalf("Submit %s: %v", cmd.Kind, err)
+	}
+	return cmd.Response
+}
+
+func result(t *testing.T, response chan error) error {
+	t.Helper()
+	select {
+	case err := <-response:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("no response")
+		return nil
+	}
+}
+
+func TestBatchOrderedWithSingleBookCommands(t *testing.T) {
+	library := newTestLibrary(t, 3)
+	blocking := newBlockingLibrary(library)
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := NewJournal(path)
+	if err != nil {
+		t.Fatalf("NewJournal: %v", err)
+	}
+	// With two workers, books 1 and 3 share a worker and book 2 has the other.
+	pool := NewCommandPool(blocking, 2, 4, journal.Observe)
+
+	blocking.block(t, pool, 3)
+	batch := submit(t, pool, Command{Kind: CommandBorrowBatch, BookIDs: []int{1, 2}, MemberID: 1})
+	// Submitted after the batch, so it must run after it even though its
+	// worker is idle while the batch waits behind book 3.
+	ret := submit(t, pool, Command{Kind: CommandReturn, BookID: 2, MemberID: 1})
+	select {
+	case err := <-ret:
+		t.Fatalf("return of book 2 ran before the batch that lends it: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(blocking.release)
+	if err := result(t, batch); err != nil {
+		t.Fatalf("batch borrow: %v", err)
+	}
+	if err := result(t, ret); err != nil {
+		t.Fatalf("return after batch: %v", err)
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	journal.Close()
+
+	entries, err := ReadJournal(path)
+	if err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	replayed := newTestLibrary(t, 3)
+	applied, errs := Replay(replayed, entries)
+	if len(errs) != 0 || applied != 3 {
+		t.Fatalf("Replay applied %d with errors %v, want 3 without errors", applied, errs)
+	}
+	for id, want := range map[int]models.BookStatus{1: models.StatusBorrowed, 2: models.StatusAvailable} {
+		if book, _ := replayed.GetBook(id); book.Status != want {
+			t.Errorf("replayed book %d status = %q, want %q", id, book.Status, want)
+		}
+	}
+}
+
+func TestPerBookOrdering(t *testing.T) {
+	library := newTestLibrary(t, 8)
+	pool := NewCommandPool(library, 4, 64)
+	defer pool.Shutdown(context.Background())
+
+	// Each book is borrowed and returned in turn by both members; any
+	// reordering within a book makes one of these commands fail.
+	var responses []chan error
+	for round := 0; round < 5; round++ {
+		for id := 1; id <= 8; id++ {
+			for _, member := range []int{1, 2} {
+				responses = append(responses,
+					submit(t, pool, Command{Kind: CommandBorrow, BookID: id, MemberID: member}),
+					submit(t, pool, Command{Kind: CommandReturn, BookID: id, MemberID: member}))
+			}
+		}
+	}
+	for i, response := range responses {
+		if err := result(t, response); err != nil {
+			t.Fatalf("command %d: %v", i, err)
+		}
+	}
+}
+
+func TestQueueDepthAndShutdownDrains(t *testing.T) {
+	library := newTestLibrary(t, 4)
+	blocking := newBlockingLibrary(library)
+	pool := NewCommandPool(blocking, 1, 8)
+
+	blocking.block(t, pool, 4)
+	var responses []chan error
+	for id := 1; id <= 3; id++ {
+		responses = append(responses, submit(t, pool, Command{Kind: CommandBorrow, BookID: id, MemberID: 1}))
+	}
+	if depth := pool.QueueDepth(); depth != 3 {
+		t.Fatalf("QueueDepth = %d, want 3", depth)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- pool.Shutdown(context.Background()) }()
+	// Once shut down, new commands are refused while queued ones still run.
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := pool.Submit(Command{Kind: CommandBorrow, BookID: 4, MemberID: 2})
+		if err == ErrPoolClosed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Submit after Shutdown = %v, want %v", err, ErrPoolClosed)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned %v before the queue drained", err)
+	default:
+	}
+
+	close(blocking.release)
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	for id, response := range responses {
+		if err := result(t, response); err != nil {
+			t.Fatalf("queued borrow of book %d: %v", id+1, err)
+		}
+	}
+	if depth := pool.QueueDepth(); depth != 0 {
+		t.Fatalf("QueueDepth after Shutdown = %d, want 0", depth)
+	}
+	if loans := library.ListLoans(1); len(loans) != 3 {
+		t.Fatalf("member 1 has %d loans after Shutdown, want 3", len(loans))
+	}
+}
+
+func TestQueuedCommandsSkippedOnceContextEnds(t *testing.T) {
+	library := newTestLibrary(t, 3)
+	blocking := newBlockingLibrary(library)
+	pool := NewCommandPool(blocking, 1, 4)
+	defer pool.Shutdown(context.Background())
+	blocking.block(t, pool, 3)
+
+	timeoutCtx, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelTimeout()
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	timedOut := submit(t, pool, Command{Ctx: timeoutCtx, Kind: CommandBorrow, BookID: 1, MemberID: 1})
+	canceled := submit(t, pool, Command{Ctx: canceledCtx, Kind: CommandBorrow, BookID: 2, MemberID: 1})
+	cancel()
+	<-timeoutCtx.Done()
+	close(blocking.release)
+
+	if err := result(t, timedOut); !errors.Is(err, ErrRequestTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("timed out command = %v, want %v wrapping %v", err, ErrRequestTimeout, context.DeadlineExceeded)
+	}
+	if err := result(t, canceled); !errors.Is(err, ErrRequestCanceled) || errors.Is(err, ErrRequestTimeout) {
+		t.Errorf("canceled command = %v, want %v", err, ErrRequestCanceled)
+	}
+	if loans := library.ListLoans(1); len(loans) != 0 {
+		t.Errorf("skipped commands were executed: member 1 has %d loans", len(loans))
+	}
+	if n := pool.metrics.expired.Value(); n != 2 {
+		t.Errorf("expired commands = %d, want 2", n)
+	}
+}
+
+func TestTimeoutsAreNotBusinessErrors(t *testing.T) {
+	library := newTestLibrary(t, 2)
+	blocking := newBlockingLibrary(library)
+	// An unbuffered queue: while the worker is busy, Submit itself waits.
+	pool := NewCommandPool(blocking, 1, 0)
+	defer pool.Shutdown(context.Background())
+
+	if err := pool.Reserve(context.Background(), 1, 1); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	err := pool.Borrow(context.Background(), 1, 2)
+	if !errors.Is(err, services.ErrBookReserved) || errors.Is(err, ErrRequestTimeout) {
+		t.Fatalf("Borrow of a reserved book = %v, want %v", err, services.ErrBookReserved)
+	}
+
+	blocking.block(t, pool, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = pool.Borrow(ctx, 1, 1)
+	if !errors.Is(err, ErrRequestTimeout) || errors.Is(err, services.ErrBookReserved) {
+		t.Fatalf("Borrow behind a busy worker = %v, want %v", err, ErrRequestTimeout)
+	}
+	close(blocking.release)
+	if book, _ := library.GetBook(1); book.Status != models.StatusReserved {
+		t.Fatalf("book 1 status = %q after the timed out borrow, want %q", book.Status, models.StatusReserved)
+	}
+}
+
+func TestShutdownWithQueuedCommandsRefusesNewOnes(t *testing.T) {
+	library := newTestLibrary(t, 3)
+	blocking := newBlockingLibrary(library)
+	pool := NewCommandPool(blocking, 1, 4)
+	blocking.block(t, pool, 3)
+	queued := submit(t, pool, Command{Kind: CommandBorrow, BookID: 1, MemberID: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := pool.Borrow(context.Background(), 2, 1); err != ErrPoolClosed {
+		t.Fatalf("Borrow after Shutdown = %v, want %v", err, ErrPoolClosed)
+	}
+
+	close(blocking.release)
+	if err := result(t, queued); err != nil {
+		t.Fatalf("queued borrow: %v", err)
+	}
+}
+
+func TestShutdownReleasesSubmitBlockedOnFullQueue(t *testing.T) {
+	library := newTestLibrary(t, 3)
+	blocking := newBlockingLibrary(library)
+	pool := NewCommandPool(blocking, 1, 1)
+	blocking.block(t, pool, 3)
+	queued := submit(t, pool, Command{Kind: CommandBorrow, BookID: 1, MemberID: 1})
+
+	// The queue is full, so this Submit blocks without a deadline of its own.
+	blocked := make(chan error, 1)
+	go func() { blocked <- pool.Submit(Command{Kind: CommandBorrow, BookID: 2, MemberID: 1}) }()
+	select {
+	case err := <-blocked:
+		t.Fatalf("Submit on a full queue returned %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- pool.Shutdown(ctx) }()
+	select {
+	case err := <-shutdown:
+		if err != context.DeadlineExceeded {
+			t.Fatalf("Shutdown = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown ignored its deadline while a Submit was blocked")
+	}
+	select {
+	case err := <-blocked:
+		if err != ErrPoolClosed {
+			t.Fatalf("blocked Submit = %v, want %v", err, ErrPoolClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked Submit was not released by Shutdown")
+	}
+
+	close(blocking.release)
+	if err := result(t, queued); err != nil {
+		t.Fatalf("queued borrow: %v", err)
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown: %v", err)
+	}
+	if loans := library.ListLoans(1); len(loans) != 1 {
+		t.Fatalf("member 1 has %d loans, want 1", len(loans))
+	}
+}
@@ -0,0 +1,147 @@
+package concurrency
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"library_management/models"
+	"library_management/services"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry is the persisted form of an executed command.
+type JournalEntry struct {
+	Kind      CommandKind       `json:"kind"`
+	BookID    int               `json:"book_id,omitempty"`
+	BookIDs   []int             `json:"book_ids,omitempty"`
+	MemberID  int               `json:"member_id,omitempty"`
+	Book      *models.Book      `json:"book,omitempty"`
+	Member    *models.Member    `json:"member,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Suspended bool              `json:"suspended,omitempty"`
+	Status    models.BookStatus `json:"status,omitempty"`
+	Branch    int               `json:"branch,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Time      time.Time         `json:"time"`
+}
+
+// Command rebuilds the command an entry was recorded from.
+func (e JournalEntry) Command() Command {
+	cmd := Command{
+		Kind: e.Kind, BookID: e.BookID, BookIDs: e.BookIDs, MemberID: e.MemberID,
+		Name: e.Name, Suspended: e.Suspended, Status: e.Status, Branch: e.Branch,
+	}
+	if e.Book != nil {
+		cmd.Book = *e.Book
+	}
+	if e.Member != nil {
+		cmd.Member = *e.Member
+	}
+	return cmd
+}
+
+// Journal is an append-only JSON-lines log of executed commands, including
+// the members and branches added at runtime. Commands for the same book are
+// recorded in execution order, and callers wait for a member or branch to be
+// added before using it, so replay applies every command after the ones it
+// depends on.
+type Journal struct {
+	file *os.File
+	mu   sync.Mutex // Serializes writes
+}
+
+// NewJournal opens (or creates) the journal at path for appending.
+func NewJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{file: file}, nil
+}
+
+// Observe records a command and its result. It has the Observer signature so
+// it can be passed to NewCommandPool.
+func (j *Journal) Observe(cmd Command, err error) {
+	entry := JournalEntry{
+		Kind:      cmd.Kind,
+		BookID:    cmd.bookID(),
+		BookIDs:   cmd.BookIDs,
+		MemberID:  cmd.memberID(),
+		Name:      cmd.Name,
+		Suspended: cmd.Suspended,
+		Status:    cmd.Status,
+		Branch:    cmd.Branch,
+		Time:      time.Now(),
+	}
+	switch cmd.Kind {
+	case CommandAddBook:
+		book := cmd.Book
+		entry.Book = &book
+	case CommandAddMember:
+		member := cmd.Member
+		entry.Member = &member
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	line, jerr := json.Marshal(entry)
+	if jerr != nil {
+		log.Printf("journal: %v", jerr)
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, werr := j.file.Write(append(line, '\n')); werr != nil {
+		log.Printf("journal: %v", werr)
+	}
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// ReadJournal loads every entry of the journal at path, oldest first.
+func ReadJournal(path string) ([]JournalEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []JournalEntry{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("journal line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Replay applies the entries that originally succeeded to the library, in
+// order. It returns how many were applied and the errors of those that failed
+// this time, e.g. because a reservation had expired between two commands.
+func Replay(library services.LibraryManager, entries []JournalEntry) (int, []error) {
+	applied := 0
+	var errs []error
+	for i, e := range entries {
+		if e.Error != "" {
+			continue
+		}
+		if err := e.Command().Execute(library); err != nil {
+			errs = append(errs, fmt.Errorf("entry %d (%s book %d): %w", i+1, e.Kind, e.BookID, err))
+			continue
+		}
+		applied++
+	}
+	return applied, errs
+}
@@ -0,0 +1,152 @@
+package concurrency
+
+import (
+	"context"
+	"library_management/models"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJournalReplayRoundTrip(t *testing.T) {
+	library := newTestLibrary(t, 3)
+	library.AddBranch(models.Branch{ID: 2, Name: "East"})
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := NewJournal(path)
+	if err != nil {
+		t.Fatalf("NewJournal: %v", err)
+	}
+	pool := NewCommandPool(library, 2, 8, journal.Observe)
+
+	commands := []struct {
+		cmd  Command
+		fail bool
+	}{
+		{cmd: Command{Kind: CommandAddBook, Book: models.Book{ID: 4, Title: "Added", Author: "Someone"}}},
+		{cmd: Command{Kind: CommandAddBook, Book: models.Book{ID: 4, Title: "Duplicate"}}, fail: true},
+		{cmd: Command{Kind: CommandBorrow, BookID: 1, MemberID: 1}},
+		{cmd: Command{Kind: CommandBorrow, BookID: 1, MemberID: 2}, fail: true},
+		{cmd: Command{Kind: CommandReserve, BookID: 2, MemberID: 2}},
+		{cmd: Command{Kind: CommandBorrowBatch, BookIDs: []int{3, 4}, MemberID: 2}},
+		{cmd: Command{Kind: CommandReturnBatch, BookIDs: []int{3}, MemberID: 2}},
+		{cmd: Command{Kind: CommandReturn, BookID: 1, MemberID: 1, Branch: 2}},
+		{cmd: Command{Kind: CommandReceive, BookID: 1, Branch: 1}},
+		{cmd: Command{Kind: CommandTransfer, BookID: 3, Branch: 2}},
+		{cmd: Command{Kind: CommandUpdateStatus, BookID: 4, Status: models.StatusLost}},
+		{cmd: Command{Kind: CommandRemoveBook, BookID: 99}, fail: true},
+	}
+	for i, c := range commands {
+		err := pool.Do(context.Background(), c.cmd)
+		if (err != nil) != c.fail {
+			t.Fatalf("command %d (%s) = %v, want failure %t", i, c.cmd.Kind, err, c.fail)
+		}
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	journal.Close()
+
+	entries, err := ReadJournal(path)
+	if err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	if len(entries) != len(commands) {
+		t.Fatalf("journal has %d entries, want %d", len(entries), len(commands))
+	}
+	for i, e := range entries {
+		if (e.Error != "") != commands[i].fail {
+			t.Errorf("entry %d (%s) error %q, want failure %t", i, e.Kind, e.Error, commands[i].fail)
+		}
+	}
+
+	replayed := newTestLibrary(t, 3)
+	replayed.AddBranch(models.Branch{ID: 2, Name: "East"})
+	applied, errs := Replay(replayed, entries)
+	if want := 9; applied != want || len(errs) != 0 {
+		t.Fatalf("Replay applied %d with errors %v, want %d without errors", applied, errs, want)
+	}
+	// Reservation holds and loan dates depend on when a command ran, so
+	// compare what replay is meant to restore.
+	type state struct {
+		Status      models.BookStatus
+		ReservedBy  int
+		Branch      int
+		Destination int
+	}
+	snapshot := func(books []models.Book) map[int]state {
+		m := make(map[int]state)
+		for _, b := range books {
+			m[b.ID] = state{b.Status, b.ReservedBy, b.Branch, b.Destination}
+		}
+		return m
+	}
+	if got, want := snapshot(replayed.ListBooks()), snapshot(library.ListBooks()); !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed books = %+v, want %+v", got, want)
+	}
+	for _, member := range []int{1, 2} {
+		if got, want := len(replayed.ListLoans(member)), len(library.ListLoans(member)); got != want {
+			t.Errorf("member %d has %d loans after replay, want %d", member, got, want)
+		}
+	}
+}
+
+func TestJournalReplaysMembersAndBranches(t *testing.T) {
+	library := newTestLibrary(t, 2)
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := NewJournal(path)
+	if err != nil {
+		t.Fatalf("NewJournal: %v", err)
+	}
+	pool := NewCommandPool(library, 2, 8, journal.Observe)
+
+	// Member 3 and branch 2 only exist because of commands run at runtime,
+	// so replay must add them before the loan and transfer that use them.
+	for i, cmd := range []Command{
+		{Kind: CommandAddMember, Member: models.Member{ID: 3, Name: "Carol", Loans: []models.Loan{}}},
+		{Kind: CommandAddBranch, Branch: 2, Name: "East"},
+		{Kind: CommandBorrow, BookID: 1, MemberID: 3},
+		{Kind: CommandTransfer, BookID: 2, Branch: 2},
+		{Kind: CommandUpdateMember, MemberID: 3, Name: "Carol Smith"},
+		{Kind: CommandSuspend, MemberID: 3, Suspended: true},
+		{Kind: CommandAddMember, Member: models.Member{ID: 4, Name: "Dave", Loans: []models.Loan{}}},
+		{Kind: CommandRemoveMember, MemberID: 4},
+	} {
+		if err := pool.Do(context.Background(), cmd); err != nil {
+			t.Fatalf("command %d (%s): %v", i, cmd.Kind, err)
+		}
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	journal.Close()
+
+	entries, err := ReadJournal(path)
+	if err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	replayed := newTestLibrary(t, 2)
+	if applied, errs := Replay(replayed, entries); applied != 8 || len(errs) != 0 {
+		t.Fatalf("Replay applied %d with errors %v, want 8 without errors", applied, errs)
+	}
+
+	member, err := replayed.GetMember(3)
+	if err != nil {
+		t.Fatalf("member 3 was not replayed: %v", err)
+	}
+	if member.Name != "Carol Smith" || !member.Suspended || len(member.Loans) != 1 || member.Loans[0].BookID != 1 {
+		t.Errorf("replayed member 3 = %+v, want Carol Smith, suspended, with book 1 on loan", member)
+	}
+	if _, err := replayed.GetMember(4); err == nil {
+		t.Error("removed member 4 was replayed")
+	}
+	if got, want := replayed.ListBranches(), library.ListBranches(); !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed branches = %+v, want %+v", got, want)
+	}
+	book, err := replayed.GetBook(2)
+	if err != nil {
+		t.Fatalf("GetBook(2): %v", err)
+	}
+	if book.Status != models.StatusInTransit || book.Destination != 2 {
+		t.Errorf("replayed book 2 is %s to branch %d, want in transit to branch 2", book.Status, book.Destination)
+	}
+}
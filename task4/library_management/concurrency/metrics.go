@@ -0,0 +1,40 @@
+package concurrency
+
+import (
+	"library_management/metrics"
+	"time"
+)
+
+// poolMetrics counts the commands a CommandPool processes. The counters are
+// always kept; RegisterMetrics exposes them.
+type poolMetrics struct {
+	commands *metrics.CounterVec // Executed commands by kind
+	failures *metrics.CounterVec // Executed commands that returned an error, by kind
+	expired  *metrics.Counter    // Commands whose context ended while queued
+	duration *metrics.Histogram
+}
+
+func newPoolMetrics() *poolMetrics {
+	return &poolMetrics{
+		commands: metrics.NewCounterVec("library_commands_total", "Commands executed by the command pool.", "kind"),
+		failures: metrics.NewCounterVec("library_command_failures_total", "Commands that returned an error.", "kind"),
+		expired:  metrics.NewCounter("library_commands_expired_total", "Commands skipped because they timed out or were canceled while queued."),
+		duration: metrics.NewHistogram("library_command_duration_seconds", "Time spent executing a command."),
+	}
+}
+
+// observe records one executed command.
+func (m *poolMetrics) observe(cmd Command, err error, took time.Duration) {
+	m.commands.Inc(string(cmd.Kind))
+	if err != nil {
+		m.failures.Inc(string(cmd.Kind))
+	}
+	m.duration.Observe(took.Seconds())
+}
+
+// RegisterMetrics adds the pool's metrics, including its queue depth, to r.
+func (p *CommandPool) RegisterMetrics(r *metrics.Registry) {
+	depth := metrics.NewGaugeFunc("library_command_queue_depth", "Commands waiting for a worker.",
+		func() float64 { return float64(p.QueueDepth()) })
+	r.Register(depth, p.metrics.commands, p.metrics.failures, p.metrics.expired, p.metrics.duration)
+}
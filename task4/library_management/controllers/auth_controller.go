@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"library_management/auth"
+	"strconv"
+	"strings"
+)
+
+// createLibrarian asks for the first librarian account. It returns false if
+// input ends before an account is created.
+func createLibrarian(reader *bufio.Reader, accounts *auth.Store) bool {
+	fmt.Println("No librarian account exists yet. Create one to continue.")
+	for {
+		fmt.Print("Choose a username: ")
+		username, err := reader.ReadString('\n')
+		if err != nil {
+			return false
+		}
+		fmt.Printf("Choose a password (at least %d characters): ", auth.MinPasswordLength)
+		password, err := reader.ReadString('\n')
+		if err != nil {
+			return false
+		}
+		if _, err := accounts.AddLibrarian(username, strings.TrimSpace(password)); err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		fmt.Println("Librarian account created.")
+		return true
+	}
+}
+
+// login asks for credentials until they are valid. Members log in with
+// their member ID and PIN. It returns false if input ends.
+func login(reader *bufio.Reader, accounts *auth.Store) (auth.Account, bool) {
+	for {
+		fmt.Println("\n--- Log In ---")
+		fmt.Print("Username or Member ID: ")
+		username, err := reader.ReadString('\n')
+		if err != nil {
+			return auth.Account{}, false
+		}
+		fmt.Print("Password or PIN: ")
+		secret, err := reader.ReadString('\n')
+		if err != nil {
+			return auth.Account{}, false
+		}
+
+		account, err := accounts.Login(strings.TrimSpace(username), strings.TrimSpace(secret))
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			fmt.Println("Invalid username or password.")
+			continue
+		}
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		if account.IsLibrarian() {
+			fmt.Printf("Welcome, %s (librarian).\n", account.Username)
+		} else {
+			fmt.Printf("Welcome, member %d.\n", account.MemberID)
+		}
+		return account, true
+	}
+}
+
+// readMemberID returns the member an action is for: members always act for
+// themselves, librarians are asked.
+func readMemberID(reader *bufio.Reader, account auth.Account) (int, bool) {
+	if !account.IsLibrarian() {
+		return account.MemberID, true
+	}
+	fmt.Print("Enter Member ID: ")
+	input, _ := reader.ReadString('\n')
+	memberID, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		fmt.Println("Invalid Member ID")
+		return 0, false
+	}
+	return memberID, true
+}
+
+// setMemberPIN asks for a member's PIN; blank input leaves it unchanged.
+func setMemberPIN(reader *bufio.Reader, accounts *auth.Store, memberID int, prompt string) {
+	fmt.Print(prompt)
+	pin, _ := reader.ReadString('\n')
+	pin = strings.TrimSpace(pin)
+	if pin == "" {
+		return
+	}
+	if _, err := accounts.SetMemberPIN(memberID, pin); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("PIN set; the member can now log in with their member ID.")
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"bufio"
+	"fmt"
+	"library_management/concurrency"
+	"library_management/models"
+	"library_management/services"
+	"strconv"
+	"strings"
+)
+
+// branches lists branches and the books in transit, and lets a librarian add
+// branches, send books to another branch and receive them on arrival.
+func branches(reader *bufio.Reader, library *services.Library, pool *concurrency.CommandPool) {
+	fmt.Println("1. List Branches")
+	fmt.Println("2. Add Branch")
+	fmt.Println("3. Transfer Book")
+	fmt.Println("4. Receive Book")
+	fmt.Println("5. List Books in Transit")
+	fmt.Print("Enter your choice: ")
+	input, _ := reader.ReadString('\n')
+
+	switch strings.TrimSpace(input) {
+	case "1":
+		for _, branch := range library.ListBranches() {
+			fmt.Printf("ID: %d, Name: %s\n", branch.ID, branch.Name)
+		}
+	case "2":
+		id, ok := readID(reader, "Enter Branch ID: ", "Branch ID")
+		if !ok {
+			return
+		}
+		fmt.Print("Enter Branch Name: ")
+		name, _ := reader.ReadString('\n')
+		name = strings.TrimSpace(name)
+		if name == "" {
+			fmt.Println("Branch name cannot be empty.")
+			return
+		}
+		if err := runCommand(pool, concurrency.Command{Kind: concurrency.CommandAddBranch, Branch: id, Name: name}); err != nil {
+			printCommandError(err)
+			return
+		}
+		fmt.Println("Branch added successfully!")
+	case "3", "4":
+		kind, verb := concurrency.CommandTransfer, "sent"
+		if strings.TrimSpace(input) == "4" {
+			kind, verb = concurrency.CommandReceive, "received"
+		}
+		bookID, ok := readID(reader, "Enter Book ID: ", "Book ID")
+		if !ok {
+			return
+		}
+		branchID, ok := readID(reader, "Enter Branch ID: ", "Branch ID")
+		if !ok {
+			return
+		}
+		if err := runCommand(pool, concurrency.Command{Kind: kind, BookID: bookID, Branch: branchID}); err != nil {
+			printCommandError(err)
+			return
+		}
+		fmt.Printf("Book %s.\n", verb)
+	case "5":
+		found := false
+		for _, book := range library.ListBooks() {
+			if book.Status == models.StatusInTransit {
+				found = true
+				fmt.Printf("ID: %d, Title: %s, From: %d, To: %d\n", book.ID, book.Title, book.Branch, book.Destination)
+			}
+		}
+		if !found {
+			fmt.Println("No books in transit.")
+		}
+	default:
+		fmt.Println("Invalid choice.")
+	}
+}
+
+// readID prompts for a numeric ID.
+func readID(reader *bufio.Reader, prompt string, name string) (int, bool) {
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	id, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		fmt.Println("Invalid", name)
+		return 0, false
+	}
+	return id, true
+}
+
+// readBranch prompts for an optional branch ID; blank input returns 0.
+func readBranch(reader *bufio.Reader, prompt string) (int, bool) {
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(input)
+	if err != nil {
+		fmt.Println("Invalid Branch ID")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"bufio"
+	"fmt"
+	"library_management/catalog"
+	"library_management/concurrency"
+	"library_management/services"
+	"strings"
+)
+
+// ImportBooks adds the books of a CSV or JSON file through the command pool
+// and prints a summary with the rejected rows. Books whose home branch does
+// not exist are rejected too. If any book was rejected, the valid ones are
+// still added and the error wraps catalog.ErrRowsRejected.
+func ImportBooks(path string, library *services.Library, pool *concurrency.CommandPool) error {
+	books, rowErrs, err := catalog.ImportBooksFile(path, func(id int) bool {
+		_, err := library.GetBook(id)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	branches := make(map[int]bool)
+	for _, branch := range library.ListBranches() {
+		branches[branch.ID] = true
+	}
+	added := 0
+	for _, book := range books {
+		if book.HomeBranch != 0 && !branches[book.HomeBranch] {
+			fmt.Printf("Book %d: %v %d\n", book.ID, services.ErrBranchNotFound, book.HomeBranch)
+			continue
+		}
+		if err := runCommand(pool, concurrency.Command{Kind: concurrency.CommandAddBook, Book: book}); err != nil {
+			fmt.Printf("Book %d: %v\n", book.ID, err)
+			continue
+		}
+		added++
+	}
+	return printImportSummary("books", added, len(books)-added, rowErrs)
+}
+
+// ImportMembers adds the members of a CSV or JSON file through the command
+// pool and prints a summary with the rejected rows. Rejections are reported
+// like ImportBooks.
+func ImportMembers(path string, pool *concurrency.CommandPool) error {
+	members, rowErrs, err := catalog.ImportMembersFile(path, nil)
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	for _, member := range members {
+		if err := runCommand(pool, concurrency.Command{Kind: concurrency.CommandAddMember, Member: member}); err != nil {
+			fmt.Printf("Member %d: %v\n", member.ID, err)
+			continue
+		}
+		added++
+	}
+	return printImportSummary("members", added, len(members)-added, rowErrs)
+}
+
+// ExportCatalog writes every book, member and loan to a CSV or JSON file.
+// A CSV export writes the members to a second file; see catalog.ExportFile.
+func ExportCatalog(path string, library *services.Library) error {
+	snap := catalog.NewSnapshot(library.ListBooks(), library.ListMembers())
+	if err := catalog.ExportFile(path, snap); err != nil {
+		return err
+	}
+	if format, _ := catalog.FormatFromPath(path); format == catalog.FormatCSV && len(snap.Members) > 0 {
+		fmt.Printf("Exported %d books to %s and %d members to %s.\n", len(snap.Books), path, len(snap.Members), catalog.MembersPath(path))
+		return nil
+	}
+	fmt.Printf("Exported %d books and %d members to %s.\n", len(snap.Books), len(snap.Members), path)
+	return nil
+}
+
+// printImportSummary prints the outcome of an import. failed counts valid
+// rows the library refused. It returns an error if any row was rejected.
+func printImportSummary(what string, added int, failed int, rowErrs []catalog.RowError) error {
+	rejected := failed + len(rowErrs)
+	fmt.Printf("Imported %d %s, rejected %d row(s).\n", added, what, rejected)
+	for _, rowErr := range rowErrs {
+		fmt.Println("-", rowErr)
+	}
+	if rejected > 0 {
+		return fmt.Errorf("%w: %d %s not imported", catalog.ErrRowsRejected, rejected, what)
+	}
+	return nil
+}
+
+// importCatalog prompts for what to import and from which file.
+func importCatalog(reader *bufio.Reader, library *services.Library, pool *concurrency.CommandPool) {
+	fmt.Print("Import (1) Books or (2) Members: ")
+	kind, _ := reader.ReadString('\n')
+	kind = strings.TrimSpace(kind)
+	if kind != "1" && kind != "2" {
+		fmt.Println("Invalid choice")
+		return
+	}
+
+	fmt.Print("Enter file path (.csv or .json): ")
+	path, _ := reader.ReadString('\n')
+	path = strings.TrimSpace(path)
+
+	var err error
+	if kind == "1" {
+		err = ImportBooks(path, library, pool)
+	} else {
+		err = ImportMembers(path, pool)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+}
+
+// exportCatalog prompts for the export file.
+func exportCatalog(reader *bufio.Reader, library *services.Library) {
+	fmt.Print("Enter export file path (.csv or .json): ")
+	path, _ := reader.ReadString('\n')
+	if err := ExportCatalog(strings.TrimSpace(path), library); err != nil {
+		fmt.Println("Error:", err)
+	}
+}
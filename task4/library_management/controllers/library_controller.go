@@ -2,30 +2,89 @@ package controllers
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"library_management/auth"
+	"library_management/concurrency"
+	"library_management/events"
 	"library_management/models"
+	"library_management/notifications"
 	"library_management/services"
-	"library_management/concurrency"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// LibraryController provides a console interface to interact with the library.
-func LibraryController(library *services.Library, resChan chan concurrency.ReservationRequest) {
+// commandTimeout bounds how long the console waits for the command workers.
+const commandTimeout = 3 * time.Second
+
+// menuItem is an entry of the console menu; entries are numbered from 1.
+type menuItem struct {
+	label         string
+	librarianOnly bool
+}
+
+// menu lists the console options. Members only see, and may only choose,
+// the entries that are not librarianOnly.
+var menu = []menuItem{
+	{"Add Book", true},
+	{"Remove Book", true},
+	{"Borrow Book", false},
+	{"Return Book", false},
+	{"List Available Books", false},
+	{"List Borrowed Books by Member", false},
+	{"Add Member", true},
+	{"Reserve Book", false},
+	{"List All Books", false},
+	{"Search Books", false},
+	{"View Audit Trail", true},
+	{"Notification Preferences", false},
+	{"Update Book Status", true},
+	{"Update Member", true},
+	{"Suspend/Reinstate Member", true},
+	{"Remove Member", true},
+	{"Check Library Integrity", true},
+	{"Import Books or Members", true},
+	{"Export Catalog", true},
+	{"Circulation Reports", true},
+	{"Branches and Transfers", true},
+	{"Recommendations", false},
+	{"Log Out", false},
+	{"Exit", false},
+}
+
+// LibraryController provides a console interface to interact with the
+// library. Users log in first: librarians may use every option, members only
+// borrow, return and reserve for themselves and browse the catalog. On the
+// first run, when no librarian account exists, one is created.
+func LibraryController(library *services.Library, pool *concurrency.CommandPool, audit *events.AuditLog, accounts *auth.Store) {
 	reader := bufio.NewReader(os.Stdin)
+	if !accounts.HasLibrarian() && !createLibrarian(reader, accounts) {
+		return
+	}
+	for {
+		account, ok := login(reader, accounts)
+		if !ok {
+			return
+		}
+		if !runMenu(reader, account, library, pool, audit, accounts) {
+			return
+		}
+	}
+}
+
+// runMenu shows the menu to a logged-in user until they log out (returning
+// true) or exit (returning false).
+func runMenu(reader *bufio.Reader, account auth.Account, library *services.Library, pool *concurrency.CommandPool, audit *events.AuditLog, accounts *auth.Store) bool {
 	for {
 		fmt.Println("\n--- Library Management System ---")
-		fmt.Println("1. Add Book")
-		fmt.Println("2. Remove Book")
-		fmt.Println("3. Borrow Book")
-		fmt.Println("4. Return Book")
-		fmt.Println("5. List Available Books")
-		fmt.Println("6. List Borrowed Books by Member")
-		fmt.Println("7. Add Member")
-		fmt.Println("8. Reserve Book")
-		fmt.Println("9. List All Books")
-		fmt.Println("10. Exit")
+		for i, item := range menu {
+			if account.IsLibrarian() || !item.librarianOnly {
+				fmt.Printf("%d. %s\n", i+1, item.label)
+			}
+		}
 		fmt.Print("Enter your choice: ")
 
 		input, _ := reader.ReadString('\n')
@@ -34,36 +93,107 @@ func LibraryController(library *services.Library, resChan chan concurrency.Reser
 			fmt.Println("Invalid input, please enter a number.")
 			continue
 		}
+		if choice >= 1 && choice <= len(menu) && menu[choice-1].librarianOnly && !account.IsLibrarian() {
+			fmt.Println("This option is only available to librarians.")
+			continue
+		}
 
 		switch choice {
 		case 1:
-			addBook(reader, library)
+			addBook(reader, pool)
 		case 2:
-			removeBook(reader, library)
+			removeBook(reader, pool)
 		case 3:
-			borrowBook(reader, library)
+			borrowBook(reader, account, pool)
 		case 4:
-			returnBook(reader, library)
+			returnBook(reader, account, pool)
 		case 5:
 			listAvailableBooks(library)
 		case 6:
-			listBorrowedBooks(reader, library)
+			listBorrowedBooks(reader, account, library)
 		case 7:
-			addMember(reader, library)
+			addMember(reader, pool, accounts)
 		case 8:
-			reserveBook(reader, resChan)
+			reserveBook(reader, account, library, pool)
 		case 9:
 			library.ListAllBooks()
 		case 10:
+			searchBooks(reader, library)
+		case 11:
+			viewAuditTrail(reader, audit)
+		case 12:
+			notificationPreferences(reader, account, library)
+		case 13:
+			updateBookStatus(reader, pool)
+		case 14:
+			updateMember(reader, pool, accounts)
+		case 15:
+			suspendMember(reader, library, pool)
+		case 16:
+			removeMember(reader, pool, accounts)
+		case 17:
+			checkIntegrity(reader, library)
+		case 18:
+			importCatalog(reader, library, pool)
+		case 19:
+			exportCatalog(reader, library)
+		case 20:
+			circulationReport(reader, library, audit)
+		case 21:
+			branches(reader, library, pool)
+		case 22:
+			recommendations(reader, account, library)
+		case 23:
+			fmt.Println("Logged out.")
+			return true
+		case 24:
 			fmt.Println("Exiting...")
-			return
+			return false
 		default:
 			fmt.Println("Invalid choice, please try again.")
 		}
 	}
 }
 
-func addBook(reader *bufio.Reader, library *services.Library) {
+// runCommand sends a command to the worker pool and waits for its result.
+func runCommand(pool *concurrency.CommandPool, cmd concurrency.Command) error {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	return pool.Do(ctx, cmd)
+}
+
+// printCommandError reports a failed command, explaining timeouts separately
+// from library errors and listing the reason for each book of a rejected batch.
+func printCommandError(err error) {
+	if errors.Is(err, concurrency.ErrRequestTimeout) {
+		fmt.Println("Error: the library is busy, please try again.")
+		return
+	}
+	var batchErr *services.BatchError
+	if errors.As(err, &batchErr) {
+		fmt.Println("Error: no books were processed:")
+		for _, item := range batchErr.Items {
+			fmt.Println("-", item)
+		}
+		return
+	}
+	fmt.Println("Error:", err)
+}
+
+// parseIDList parses a comma-separated list of IDs such as "101, 102".
+func parseIDList(input string) ([]int, error) {
+	var ids []int
+	for _, field := range strings.Split(input, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func addBook(reader *bufio.Reader, pool *concurrency.CommandPool) {
 	fmt.Print("Enter Book ID: ")
 	idStr, _ := reader.ReadString('\n')
 	id, err := strconv.Atoi(strings.TrimSpace(idStr))
@@ -85,11 +215,14 @@ func addBook(reader *bufio.Reader, library *services.Library) {
 		Title:  title,
 		Author: author,
 	}
-	library.AddBook(book)
+	if err := runCommand(pool, concurrency.Command{Kind: concurrency.CommandAddBook, Book: book}); err != nil {
+		printCommandError(err)
+		return
+	}
 	fmt.Println("Book added successfully!")
 }
 
-func removeBook(reader *bufio.Reader, library *services.Library) {
+func removeBook(reader *bufio.Reader, pool *concurrency.CommandPool) {
 	fmt.Print("Enter Book ID to remove: ")
 	idStr, _ := reader.ReadString('\n')
 	id, err := strconv.Atoi(strings.TrimSpace(idStr))
@@ -97,58 +230,105 @@ func removeBook(reader *bufio.Reader, library *services.Library) {
 		fmt.Println("Invalid ID")
 		return
 	}
-	library.RemoveBook(id)
+	if err := runCommand(pool, concurrency.Command{Kind: concurrency.CommandRemoveBook, BookID: id}); err != nil {
+		printCommandError(err)
+		return
+	}
 	fmt.Println("Book removed successfully!")
 }
 
-func borrowBook(reader *bufio.Reader, library *services.Library) {
-	fmt.Print("Enter Book ID to borrow: ")
+func borrowBook(reader *bufio.Reader, account auth.Account, pool *concurrency.CommandPool) {
+	fmt.Print("Enter Book ID(s) to borrow, separated by commas: ")
 	bookIDStr, _ := reader.ReadString('\n')
-	bookID, err := strconv.Atoi(strings.TrimSpace(bookIDStr))
+	bookIDs, err := parseIDList(bookIDStr)
 	if err != nil {
 		fmt.Println("Invalid Book ID")
 		return
 	}
 
-	fmt.Print("Enter Member ID: ")
-	memberIDStr, _ := reader.ReadString('\n')
-	memberID, err := strconv.Atoi(strings.TrimSpace(memberIDStr))
-	if err != nil {
-		fmt.Println("Invalid Member ID")
+	memberID, ok := readMemberID(reader, account)
+	if !ok {
 		return
 	}
 
-	if err := library.BorrowBook(bookID, memberID); err != nil {
-		fmt.Println("Error:", err)
+	branchID, ok := readBranch(reader, "Enter Branch ID (blank for where the book is): ")
+	if !ok {
+		return
+	}
+
+	// Several books are processed as one batch: all of them or none.
+	cmd := concurrency.Command{Kind: concurrency.CommandBorrow, BookID: bookIDs[0], MemberID: memberID, Branch: branchID}
+	if len(bookIDs) > 1 {
+		cmd = concurrency.Command{Kind: concurrency.CommandBorrowBatch, BookIDs: bookIDs, MemberID: memberID, Branch: branchID}
+	}
+	if err := runCommand(pool, cmd); err != nil {
+		printCommandError(err)
+	} else if len(bookIDs) > 1 {
+		fmt.Println("Books borrowed successfully!")
 	} else {
 		fmt.Println("Book borrowed successfully!")
 	}
 }
 
-func returnBook(reader *bufio.Reader, library *services.Library) {
-	fmt.Print("Enter Book ID to return: ")
+func returnBook(reader *bufio.Reader, account auth.Account, pool *concurrency.CommandPool) {
+	fmt.Print("Enter Book ID(s) to return, separated by commas: ")
 	bookIDStr, _ := reader.ReadString('\n')
-	bookID, err := strconv.Atoi(strings.TrimSpace(bookIDStr))
+	bookIDs, err := parseIDList(bookIDStr)
 	if err != nil {
 		fmt.Println("Invalid Book ID")
 		return
 	}
 
-	fmt.Print("Enter Member ID: ")
-	memberIDStr, _ := reader.ReadString('\n')
-	memberID, err := strconv.Atoi(strings.TrimSpace(memberIDStr))
-	if err != nil {
-		fmt.Println("Invalid Member ID")
+	memberID, ok := readMemberID(reader, account)
+	if !ok {
 		return
 	}
 
-	if err := library.ReturnBook(bookID, memberID); err != nil {
-		fmt.Println("Error:", err)
+	branchID, ok := readBranch(reader, "Enter Branch ID (blank for where it was borrowed): ")
+	if !ok {
+		return
+	}
+
+	// Several books are processed as one batch: all of them or none.
+	cmd := concurrency.Command{Kind: concurrency.CommandReturn, BookID: bookIDs[0], MemberID: memberID, Branch: branchID}
+	if len(bookIDs) > 1 {
+		cmd = concurrency.Command{Kind: concurrency.CommandReturnBatch, BookIDs: bookIDs, MemberID: memberID, Branch: branchID}
+	}
+	if err := runCommand(pool, cmd); err != nil {
+		printCommandError(err)
+	} else if len(bookIDs) > 1 {
+		fmt.Println("Books returned successfully!")
 	} else {
 		fmt.Println("Book returned successfully!")
 	}
 }
 
+// updateBookStatus lets a librarian mark a book lost, damaged, in repair,
+// withdrawn or available again.
+func updateBookStatus(reader *bufio.Reader, pool *concurrency.CommandPool) {
+	fmt.Print("Enter Book ID: ")
+	idStr, _ := reader.ReadString('\n')
+	id, err := strconv.Atoi(strings.TrimSpace(idStr))
+	if err != nil {
+		fmt.Println("Invalid ID")
+		return
+	}
+
+	fmt.Print("Enter new status (Available, Lost, Damaged, In Repair, Withdrawn): ")
+	statusStr, _ := reader.ReadString('\n')
+	status, err := models.ParseBookStatus(strings.TrimSpace(statusStr))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if err := runCommand(pool, concurrency.Command{Kind: concurrency.CommandUpdateStatus, BookID: id, Status: status}); err != nil {
+		printCommandError(err)
+		return
+	}
+	fmt.Println("Book status updated successfully!")
+}
+
 func listAvailableBooks(library *services.Library) {
 	books := library.ListAvailableBooks()
 	if len(books) == 0 {
@@ -161,26 +341,89 @@ func listAvailableBooks(library *services.Library) {
 	}
 }
 
-func listBorrowedBooks(reader *bufio.Reader, library *services.Library) {
-	fmt.Print("Enter Member ID: ")
-	memberIDStr, _ := reader.ReadString('\n')
-	memberID, err := strconv.Atoi(strings.TrimSpace(memberIDStr))
-	if err != nil {
-		fmt.Println("Invalid Member ID")
+func listBorrowedBooks(reader *bufio.Reader, account auth.Account, library *services.Library) {
+	memberID, ok := readMemberID(reader, account)
+	if !ok {
 		return
 	}
-	books := library.ListBorrowedBooks(memberID)
-	if len(books) == 0 {
+	loans := library.ListLoans(memberID)
+	if len(loans) == 0 {
 		fmt.Println("No borrowed books for this member.")
 		return
 	}
 	fmt.Println("Borrowed Books:")
+	for _, loan := range loans {
+		book, err := library.GetBook(loan.BookID)
+		if err != nil {
+			continue
+		}
+		fmt.Printf("ID: %d, Title: %s, Author: %s, Due: %s\n", book.ID, book.Title, book.Author, loan.DueDate.Format("2006-01-02"))
+	}
+}
+
+func searchBooks(reader *bufio.Reader, library *services.Library) {
+	fmt.Print("Enter title or author to search: ")
+	query, _ := reader.ReadString('\n')
+	query = strings.TrimSpace(query)
+	if query == "" {
+		fmt.Println("Search query cannot be empty")
+		return
+	}
+
+	books := library.SearchBooks(query)
+	if len(books) == 0 {
+		fmt.Println("No matching books.")
+		return
+	}
+	fmt.Println("Matching Books:")
 	for _, book := range books {
-		fmt.Printf("ID: %d, Title: %s, Author: %s\n", book.ID, book.Title, book.Author)
+		fmt.Printf("ID: %d, Title: %s, Author: %s, Status: %s\n", book.ID, book.Title, book.Author, book.Status)
 	}
 }
 
-func addMember(reader *bufio.Reader, library *services.Library) {
+// recommendations shows what readers of a book also borrowed, or suggestions
+// for a member based on their loan history.
+func recommendations(reader *bufio.Reader, account auth.Account, library *services.Library) {
+	fmt.Println("1. Readers of a book also borrowed")
+	fmt.Println("2. Suggestions for a member")
+	fmt.Print("Enter your choice: ")
+	input, _ := reader.ReadString('\n')
+
+	var recs []services.Recommendation
+	var err error
+	switch strings.TrimSpace(input) {
+	case "1":
+		bookID, ok := readID(reader, "Enter Book ID: ", "Book ID")
+		if !ok {
+			return
+		}
+		recs, err = library.AlsoBorrowed(bookID, services.DefaultRecommendations)
+	case "2":
+		memberID, ok := readMemberID(reader, account)
+		if !ok {
+			return
+		}
+		recs, err = library.RecommendFor(memberID, services.DefaultRecommendations)
+	default:
+		fmt.Println("Invalid choice.")
+		return
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(recs) == 0 {
+		fmt.Println("No recommendations yet; they appear as members borrow books.")
+		return
+	}
+	fmt.Println("Recommended Books:")
+	for _, rec := range recs {
+		fmt.Printf("ID: %d, Title: %s, Author: %s, Status: %s (shared readers: %d)\n",
+			rec.Book.ID, rec.Book.Title, rec.Book.Author, rec.Book.Status, rec.Score)
+	}
+}
+
+func addMember(reader *bufio.Reader, pool *concurrency.CommandPool, accounts *auth.Store) {
 	fmt.Print("Enter Member ID: ")
 	idStr, _ := reader.ReadString('\n')
 	id, err := strconv.Atoi(strings.TrimSpace(idStr))
@@ -194,16 +437,112 @@ func addMember(reader *bufio.Reader, library *services.Library) {
 	name = strings.TrimSpace(name)
 
 	member := models.Member{
-		ID:            id,
-		Name:          name,
-		BorrowedBooks: []models.Book{},
+		ID:    id,
+		Name:  name,
+		Loans: []models.Loan{},
+	}
+	if err := runCommand(pool, concurrency.Command{Kind: concurrency.CommandAddMember, Member: member}); err != nil {
+		printCommandError(err)
+		return
 	}
-	library.AddMember(member)
 	fmt.Println("Member added successfully!")
+	setMemberPIN(reader, accounts, id, "Enter a PIN for the member to log in with (blank to skip): ")
+}
+
+func updateMember(reader *bufio.Reader, pool *concurrency.CommandPool, accounts *auth.Store) {
+	fmt.Print("Enter Member ID: ")
+	idStr, _ := reader.ReadString('\n')
+	id, err := strconv.Atoi(strings.TrimSpace(idStr))
+	if err != nil {
+		fmt.Println("Invalid Member ID")
+		return
+	}
+
+	fmt.Print("Enter New Member Name: ")
+	name, _ := reader.ReadString('\n')
+	name = strings.TrimSpace(name)
+	if name == "" {
+		fmt.Println("Name cannot be empty")
+		return
+	}
+
+	if err := runCommand(pool, concurrency.Command{Kind: concurrency.CommandUpdateMember, MemberID: id, Name: name}); err != nil {
+		printCommandError(err)
+		return
+	}
+	fmt.Println("Member updated successfully!")
+	setMemberPIN(reader, accounts, id, "Enter a new PIN (blank to keep): ")
+}
+
+// suspendMember toggles whether a member may borrow and reserve books.
+func suspendMember(reader *bufio.Reader, library *services.Library, pool *concurrency.CommandPool) {
+	fmt.Print("Enter Member ID: ")
+	idStr, _ := reader.ReadString('\n')
+	id, err := strconv.Atoi(strings.TrimSpace(idStr))
+	if err != nil {
+		fmt.Println("Invalid Member ID")
+		return
+	}
+
+	member, err := library.GetMember(id)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	cmd := concurrency.Command{Kind: concurrency.CommandSuspend, MemberID: id, Suspended: !member.Suspended}
+	if err := runCommand(pool, cmd); err != nil {
+		printCommandError(err)
+		return
+	}
+	if member.Suspended {
+		fmt.Println("Member reinstated successfully!")
+	} else {
+		fmt.Println("Member suspended successfully!")
+	}
+}
+
+func removeMember(reader *bufio.Reader, pool *concurrency.CommandPool, accounts *auth.Store) {
+	fmt.Print("Enter Member ID to remove: ")
+	idStr, _ := reader.ReadString('\n')
+	id, err := strconv.Atoi(strings.TrimSpace(idStr))
+	if err != nil {
+		fmt.Println("Invalid Member ID")
+		return
+	}
+	if err := runCommand(pool, concurrency.Command{Kind: concurrency.CommandRemoveMember, MemberID: id}); err != nil {
+		printCommandError(err)
+		return
+	}
+	if err := accounts.RemoveMember(id); err != nil {
+		fmt.Println("Error removing the member's login:", err)
+	}
+	fmt.Println("Member removed successfully!")
 }
 
-// reserveBook sends a reservation request to the reservation worker via channel.
-func reserveBook(reader *bufio.Reader, resChan chan concurrency.ReservationRequest) {
+// checkIntegrity reports disagreements between books and members and
+// optionally repairs them.
+func checkIntegrity(reader *bufio.Reader, library *services.Library) {
+	issues := library.CheckIntegrity(false)
+	if len(issues) == 0 {
+		fmt.Println("No integrity issues found.")
+		return
+	}
+	fmt.Println("Integrity Issues:")
+	for _, issue := range issues {
+		fmt.Println("-", issue)
+	}
+
+	fmt.Print("Repair these issues? (y/n): ")
+	answer, _ := reader.ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		return
+	}
+	repaired := library.CheckIntegrity(true)
+	fmt.Printf("Repaired %d issue(s).\n", len(repaired))
+}
+
+// reserveBook sends a reservation command to the command worker pool.
+func reserveBook(reader *bufio.Reader, account auth.Account, library *services.Library, pool *concurrency.CommandPool) {
 	fmt.Print("Enter Book ID to reserve: ")
 	bookIDStr, _ := reader.ReadString('\n')
 	bookID, err := strconv.Atoi(strings.TrimSpace(bookIDStr))
@@ -212,7 +551,32 @@ func reserveBook(reader *bufio.Reader, resChan chan concurrency.ReservationReque
 		return
 	}
 
-	fmt.Print("Enter Member ID: ")
+	memberID, ok := readMemberID(reader, account)
+	if !ok {
+		return
+	}
+
+	branchID, ok := readBranch(reader, "Enter pickup Branch ID (blank for where the book is): ")
+	if !ok {
+		return
+	}
+
+	err = runCommand(pool, concurrency.Command{Kind: concurrency.CommandReserve, BookID: bookID, MemberID: memberID, Branch: branchID})
+	if err != nil {
+		printCommandError(err)
+		return
+	}
+	book, err := library.GetBook(bookID)
+	if err == nil && book.Status == models.StatusInTransit {
+		fmt.Printf("Reservation successful! The book is on its way to branch %d; you will be notified when it arrives.\n", book.Destination)
+		return
+	}
+	fmt.Printf("Reservation successful! (Remember, you have %s to borrow the book.)\n", services.ReservationHold)
+}
+
+// viewAuditTrail prints the audit log entries for a member and/or book.
+func viewAuditTrail(reader *bufio.Reader, audit *events.AuditLog) {
+	fmt.Print("Enter Member ID (0 for any): ")
 	memberIDStr, _ := reader.ReadString('\n')
 	memberID, err := strconv.Atoi(strings.TrimSpace(memberIDStr))
 	if err != nil {
@@ -220,19 +584,62 @@ func reserveBook(reader *bufio.Reader, resChan chan concurrency.ReservationReque
 		return
 	}
 
-	// Prepare the reservation request.
-	req := concurrency.ReservationRequest{
-		BookID:   bookID,
-		MemberID: memberID,
-		Response: make(chan error),
+	fmt.Print("Enter Book ID (0 for any): ")
+	bookIDStr, _ := reader.ReadString('\n')
+	bookID, err := strconv.Atoi(strings.TrimSpace(bookIDStr))
+	if err != nil {
+		fmt.Println("Invalid Book ID")
+		return
 	}
 
-	// Send the request to the reservation worker.
-	resChan <- req
-	err = <-req.Response
+	entries, err := audit.Query(events.Filter{MemberID: memberID, BookID: bookID})
 	if err != nil {
-		fmt.Println("Reservation Error:", err)
-	} else {
-		fmt.Println("Reservation successful! (Remember, you have 5 seconds to borrow the book.)")
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println("No audit entries found.")
+		return
+	}
+	fmt.Println("Audit Trail:")
+	for _, e := range entries {
+		fmt.Printf("%s  %-20s Book: %d, Member: %d\n", e.Time.Format("2006-01-02 15:04:05"), e.Type, e.BookID, e.MemberID)
+	}
+}
+
+// notificationPreferences shows a member's notification settings and lets
+// them toggle one kind on or off.
+func notificationPreferences(reader *bufio.Reader, account auth.Account, library *services.Library) {
+	memberID, ok := readMemberID(reader, account)
+	if !ok {
+		return
+	}
+
+	prefs := library.NotificationPreferences(memberID)
+	fmt.Println("Notification Preferences:")
+	for i, kind := range notifications.Kinds {
+		state := "off"
+		if prefs[kind] {
+			state = "on"
+		}
+		fmt.Printf("%d. %s: %s\n", i+1, kind, state)
+	}
+
+	fmt.Print("Enter number to toggle (blank to keep): ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return
+	}
+	n, err := strconv.Atoi(input)
+	if err != nil || n < 1 || n > len(notifications.Kinds) {
+		fmt.Println("Invalid choice")
+		return
+	}
+	kind := notifications.Kinds[n-1]
+	if err := library.SetNotificationPreference(memberID, kind, !prefs[kind]); err != nil {
+		fmt.Println("Error:", err)
+		return
 	}
+	fmt.Println("Preference updated!")
 }
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"bufio"
+	"fmt"
+	"library_management/events"
+	"library_management/reports"
+	"library_management/services"
+	"os"
+	"strings"
+	"time"
+)
+
+// circulationReport builds a circulation report from the audit log for a
+// period and prints it or saves it as CSV.
+func circulationReport(reader *bufio.Reader, library *services.Library, audit *events.AuditLog) {
+	from, ok := readDate(reader, "Enter start date YYYY-MM-DD (blank for all time): ")
+	if !ok {
+		return
+	}
+	to, ok := readDate(reader, "Enter end date YYYY-MM-DD (blank for today): ")
+	if !ok {
+		return
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1) // Include the whole end day.
+	}
+
+	history, err := audit.Query(events.Filter{})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	report := reports.BuildCirculation(history, reports.Options{
+		From:  from,
+		To:    to,
+		Now:   library.Now(),
+		Loans: library.OpenLoans(),
+		Title: func(bookID int) string {
+			book, err := library.GetBook(bookID)
+			if err != nil {
+				return ""
+			}
+			return book.Title
+		},
+	})
+
+	fmt.Print("Save as CSV file (blank to print): ")
+	path, _ := reader.ReadString('\n')
+	path = strings.TrimSpace(path)
+	if path == "" {
+		if err := reports.WriteText(os.Stdout, report); err != nil {
+			fmt.Println("Error:", err)
+		}
+		return
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer file.Close()
+	if err := reports.WriteCSV(file, report); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("Report saved to", path)
+}
+
+// readDate reads an optional YYYY-MM-DD date; blank input gives the zero time.
+func readDate(reader *bufio.Reader, prompt string) (time.Time, bool) {
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return time.Time{}, true
+	}
+	date, err := time.ParseInLocation("2006-01-02", input, time.Local)
+	if err != nil {
+		fmt.Println("Invalid date")
+		return time.Time{}, false
+	}
+	return date, true
+}
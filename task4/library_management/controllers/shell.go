@@ -0,0 +1,611 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"library_management/auth"
+	"library_management/concurrency"
+	"library_management/models"
+	"library_management/services"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errExit is returned by the exit command to end an interactive session.
+var errExit = errors.New("exit")
+
+// Errors returned when the logged-in account may not run a command.
+var (
+	errLibrarianOnly = errors.New("this command is only available to librarians")
+	errOtherMember   = errors.New("members can only act for themselves")
+)
+
+// Shell executes text commands such as "borrow 101 1" or
+// "list available --json" against the library. It is the scriptable
+// alternative to the numbered menu of LibraryController and, like it, runs
+// as a logged-in account.
+type Shell struct {
+	library  *services.Library
+	pool     *concurrency.CommandPool
+	accounts *auth.Store
+	account  auth.Account
+	out      io.Writer
+	history  []string
+}
+
+// shellCommand describes one shell command. Members may only run the
+// commands that are not librarianOnly, and only for themselves.
+type shellCommand struct {
+	usage         string
+	summary       string
+	minArgs       int
+	librarianOnly bool
+	run           func(s *Shell, args []string, flags map[string]bool) error
+}
+
+// shellCommands is the shell grammar, keyed by command name.
+var shellCommands map[string]shellCommand
+
+func init() {
+	shellCommands = map[string]shellCommand{
+		"help":          {"help [command]", "Show all commands or the usage of one", 0, false, (*Shell).help},
+		"history":       {"history", "Show the commands entered in this session", 0, false, (*Shell).showHistory},
+		"exit":          {"exit", "Leave the shell", 0, false, func(*Shell, []string, map[string]bool) error { return errExit }},
+		"add-book":      {"add-book <id> <title> [author]", "Add a book (quote multi-word values)", 2, true, (*Shell).addBook},
+		"remove-book":   {"remove-book <id>", "Remove a book", 1, true, (*Shell).removeBook},
+		"borrow":        {"borrow <book-id>[,<book-id>...] <member-id> [branch-id]", "Borrow books, all or none (at a branch)", 2, false, (*Shell).borrow},
+		"return":        {"return <book-id>[,<book-id>...] <member-id> [branch-id]", "Return books, all or none (at a branch)", 2, false, (*Shell).returnBook},
+		"reserve":       {"reserve <book-id> <member-id> [pickup-branch-id]", "Reserve a book (for pickup at a branch)", 2, false, (*Shell).reserve},
+		"status":        {"status <book-id> <status>", "Change a book's lifecycle status", 2, true, (*Shell).setStatus},
+		"list":          {"list available|all|borrowed <member-id> [--json]", "List books", 1, false, (*Shell).list},
+		"search":        {"search <query> [--json]", "Search titles and authors", 1, false, (*Shell).search},
+		"add-member":    {"add-member <id> <name> [pin]", "Add a member, with a PIN to log in with (quote multi-word names)", 2, true, (*Shell).addMember},
+		"update-member": {"update-member <id> <name>", "Rename a member", 2, true, (*Shell).updateMember},
+		"suspend":       {"suspend <member-id>", "Suspend a member", 1, true, (*Shell).suspend},
+		"reinstate":     {"reinstate <member-id>", "Reinstate a suspended member", 1, true, (*Shell).reinstate},
+		"remove-member": {"remove-member <member-id>", "Remove a member", 1, true, (*Shell).removeMember},
+		"check":         {"check [--repair]", "Check library integrity", 0, true, (*Shell).check},
+		"import":        {"import books|members <file>", "Import a CSV or JSON file", 2, true, (*Shell).importFile},
+		"export":        {"export <file>", "Export the catalog to a CSV or JSON file", 1, true, (*Shell).export},
+		"branches":      {"branches", "List branches", 0, false, (*Shell).listBranches},
+		"recommend":     {"recommend book|member <id> [--json]", "Show what readers also borrowed, or suggestions for a member", 2, false, (*Shell).recommend},
+		"add-branch":    {"add-branch <id> <name>", "Add a branch", 2, true, (*Shell).addBranch},
+		"transfer":      {"transfer <book-id> <branch-id>", "Send an available book to another branch", 2, true, (*Shell).transfer},
+		"receive":       {"receive <book-id> <branch-id>", "Receive a book in transit at its destination", 2, true, (*Shell).receive},
+	}
+}
+
+// NewShell creates a shell that runs commands as account, writing its
+// output to out. Member logins are added to and removed from accounts along
+// with the members.
+func NewShell(library *services.Library, pool *concurrency.CommandPool, accounts *auth.Store, account auth.Account, out io.Writer) *Shell {
+	return &Shell{library: library, pool: pool, accounts: accounts, account: account, out: out}
+}
+
+// ShellController runs the interactive shell on stdin behind the same login
+// as the console menu, creating the first librarian account if needed.
+func ShellController(library *services.Library, pool *concurrency.CommandPool, accounts *auth.Store) {
+	reader := bufio.NewReader(os.Stdin)
+	if !accounts.HasLibrarian() && !createLibrarian(reader, accounts) {
+		return
+	}
+	account, ok := login(reader, accounts)
+	if !ok {
+		return
+	}
+	NewShell(library, pool, accounts, account, os.Stdout).RunInteractive(reader)
+}
+
+// Execute parses and runs a single command line. Blank lines and lines
+// starting with # are ignored.
+func (s *Shell) Execute(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	tokens, err := tokenize(line)
+	if err != nil {
+		return err
+	}
+	if tokens[0] != "history" {
+		s.history = append(s.history, line)
+	}
+
+	var args []string
+	flags := make(map[string]bool)
+	for _, tok := range tokens[1:] {
+		if strings.HasPrefix(tok, "--") {
+			flags[strings.TrimPrefix(tok, "--")] = true
+			continue
+		}
+		args = append(args, tok)
+	}
+
+	name := tokens[0]
+	if name == "quit" {
+		name = "exit"
+	}
+	cmd, ok := shellCommands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q (type help for a list)", tokens[0])
+	}
+	if cmd.librarianOnly && !s.account.IsLibrarian() {
+		return errLibrarianOnly
+	}
+	if len(args) < cmd.minArgs {
+		return fmt.Errorf("usage: %s", cmd.usage)
+	}
+	return cmd.run(s, args, flags)
+}
+
+// RunInteractive reads commands from in until EOF or exit, printing errors
+// without stopping.
+func (s *Shell) RunInteractive(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	fmt.Fprintln(s.out, "Library shell. Type help for a list of commands.")
+	for {
+		fmt.Fprint(s.out, "library> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(s.out)
+			return
+		}
+		err := s.Execute(scanner.Text())
+		if errors.Is(err, errExit) {
+			return
+		}
+		if err != nil {
+			fmt.Fprintln(s.out, "Error:", err)
+		}
+	}
+}
+
+// RunScript executes the commands of a script file in order and stops at
+// the first failure, returning an error naming the failing line.
+func (s *Shell) RunScript(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		err := s.Execute(scanner.Text())
+		if errors.Is(err, errExit) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Shell) help(args []string, _ map[string]bool) error {
+	if len(args) > 0 {
+		cmd, ok := shellCommands[args[0]]
+		if !ok || !s.allowed(cmd) {
+			return fmt.Errorf("unknown command %q", args[0])
+		}
+		fmt.Fprintf(s.out, "%s\n  %s\n", cmd.usage, cmd.summary)
+		return nil
+	}
+	names := make([]string, 0, len(shellCommands))
+	for name, cmd := range shellCommands {
+		if s.allowed(cmd) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(s.out, "  %-58s %s\n", shellCommands[name].usage, shellCommands[name].summary)
+	}
+	return nil
+}
+
+// allowed reports whether the logged-in account may run cmd.
+func (s *Shell) allowed(cmd shellCommand) bool {
+	return !cmd.librarianOnly || s.account.IsLibrarian()
+}
+
+// actFor checks that the logged-in account may act for memberID.
+func (s *Shell) actFor(memberID int) error {
+	if !s.account.CanActFor(memberID) {
+		return errOtherMember
+	}
+	return nil
+}
+
+func (s *Shell) showHistory([]string, map[string]bool) error {
+	for i, line := range s.history {
+		fmt.Fprintf(s.out, "%4d  %s\n", i+1, line)
+	}
+	return nil
+}
+
+func (s *Shell) addBook(args []string, _ map[string]bool) error {
+	id, err := parseArgID(args[0], "book ID")
+	if err != nil {
+		return err
+	}
+	book := models.Book{ID: id, Title: args[1]}
+	if len(args) > 2 {
+		book.Author = strings.Join(args[2:], " ")
+	}
+	return s.run(concurrency.Command{Kind: concurrency.CommandAddBook, Book: book}, "Book added.")
+}
+
+func (s *Shell) removeBook(args []string, _ map[string]bool) error {
+	id, err := parseArgID(args[0], "book ID")
+	if err != nil {
+		return err
+	}
+	return s.run(concurrency.Command{Kind: concurrency.CommandRemoveBook, BookID: id}, "Book removed.")
+}
+
+func (s *Shell) borrow(args []string, _ map[string]bool) error {
+	cmd, err := loanCommand(args, concurrency.CommandBorrow, concurrency.CommandBorrowBatch)
+	if err != nil {
+		return err
+	}
+	if err := s.actFor(cmd.MemberID); err != nil {
+		return err
+	}
+	if len(cmd.BookIDs) > 0 {
+		return s.run(cmd, "Books borrowed.")
+	}
+	return s.run(cmd, "Book borrowed.")
+}
+
+func (s *Shell) returnBook(args []string, _ map[string]bool) error {
+	cmd, err := loanCommand(args, concurrency.CommandReturn, concurrency.CommandReturnBatch)
+	if err != nil {
+		return err
+	}
+	if err := s.actFor(cmd.MemberID); err != nil {
+		return err
+	}
+	if len(cmd.BookIDs) > 0 {
+		return s.run(cmd, "Books returned.")
+	}
+	return s.run(cmd, "Book returned.")
+}
+
+func (s *Shell) reserve(args []string, _ map[string]bool) error {
+	bookID, memberID, err := parseBookMember(args)
+	if err != nil {
+		return err
+	}
+	if err := s.actFor(memberID); err != nil {
+		return err
+	}
+	branchID, err := optionalBranch(args, 2)
+	if err != nil {
+		return err
+	}
+	return s.run(concurrency.Command{Kind: concurrency.CommandReserve, BookID: bookID, MemberID: memberID, Branch: branchID}, "Book reserved.")
+}
+
+func (s *Shell) setStatus(args []string, _ map[string]bool) error {
+	id, err := parseArgID(args[0], "book ID")
+	if err != nil {
+		return err
+	}
+	status, err := models.ParseBookStatus(strings.Join(args[1:], " "))
+	if err != nil {
+		return err
+	}
+	return s.run(concurrency.Command{Kind: concurrency.CommandUpdateStatus, BookID: id, Status: status}, "Book status updated.")
+}
+
+func (s *Shell) list(args []string, flags map[string]bool) error {
+	var books []models.Book
+	switch args[0] {
+	case "available":
+		books = s.library.ListAvailableBooks()
+		sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	case "all":
+		books = s.library.ListBooks()
+	case "borrowed":
+		if len(args) < 2 {
+			return errors.New("usage: list borrowed <member-id>")
+		}
+		memberID, err := parseArgID(args[1], "member ID")
+		if err != nil {
+			return err
+		}
+		if err := s.actFor(memberID); err != nil {
+			return err
+		}
+		if _, err := s.library.GetMember(memberID); err != nil {
+			return err
+		}
+		books = s.library.ListBorrowedBooks(memberID)
+	default:
+		return fmt.Errorf("usage: %s", shellCommands["list"].usage)
+	}
+	return s.printBooks(books, flags["json"])
+}
+
+func (s *Shell) search(args []string, flags map[string]bool) error {
+	return s.printBooks(s.library.SearchBooks(strings.Join(args, " ")), flags["json"])
+}
+
+func (s *Shell) addMember(args []string, _ map[string]bool) error {
+	id, err := parseArgID(args[0], "member ID")
+	if err != nil {
+		return err
+	}
+	if len(args) > 3 {
+		return fmt.Errorf("usage: %s", shellCommands["add-member"].usage)
+	}
+	member := models.Member{ID: id, Name: args[1], Loans: []models.Loan{}}
+	if err := s.run(concurrency.Command{Kind: concurrency.CommandAddMember, Member: member}, "Member added."); err != nil {
+		return err
+	}
+	if len(args) == 3 {
+		if _, err := s.accounts.SetMemberPIN(id, args[2]); err != nil {
+			return fmt.Errorf("member %d has no login: %w", id, err)
+		}
+		fmt.Fprintln(s.out, "PIN set; the member can now log in with their member ID.")
+	}
+	return nil
+}
+
+func (s *Shell) updateMember(args []string, _ map[string]bool) error {
+	id, err := parseArgID(args[0], "member ID")
+	if err != nil {
+		return err
+	}
+	cmd := concurrency.Command{Kind: concurrency.CommandUpdateMember, MemberID: id, Name: strings.Join(args[1:], " ")}
+	return s.run(cmd, "Member updated.")
+}
+
+func (s *Shell) suspend(args []string, _ map[string]bool) error {
+	return s.setSuspended(args[0], true, "Member suspended.")
+}
+
+func (s *Shell) reinstate(args []string, _ map[string]bool) error {
+	return s.setSuspended(args[0], false, "Member reinstated.")
+}
+
+func (s *Shell) setSuspended(arg string, suspended bool, done string) error {
+	id, err := parseArgID(arg, "member ID")
+	if err != nil {
+		return err
+	}
+	return s.run(concurrency.Command{Kind: concurrency.CommandSuspend, MemberID: id, Suspended: suspended}, done)
+}
+
+func (s *Shell) removeMember(args []string, _ map[string]bool) error {
+	id, err := parseArgID(args[0], "member ID")
+	if err != nil {
+		return err
+	}
+	if err := runCommand(s.pool, concurrency.Command{Kind: concurrency.CommandRemoveMember, MemberID: id}); err != nil {
+		return err
+	}
+	if err := s.accounts.RemoveMember(id); err != nil {
+		return fmt.Errorf("member removed, but not their login: %w", err)
+	}
+	fmt.Fprintln(s.out, "Member removed.")
+	return nil
+}
+
+func (s *Shell) check(_ []string, flags map[string]bool) error {
+	issues := s.library.CheckIntegrity(flags["repair"])
+	for _, issue := range issues {
+		fmt.Fprintln(s.out, "-", issue)
+	}
+	if len(issues) > 0 && !flags["repair"] {
+		return fmt.Errorf("%d integrity issue(s) found", len(issues))
+	}
+	fmt.Fprintf(s.out, "%d integrity issue(s).\n", len(issues))
+	return nil
+}
+
+func (s *Shell) importFile(args []string, _ map[string]bool) error {
+	switch args[0] {
+	case "books":
+		return ImportBooks(args[1], s.library, s.pool)
+	case "members":
+		return ImportMembers(args[1], s.pool)
+	default:
+		return fmt.Errorf("usage: %s", shellCommands["import"].usage)
+	}
+}
+
+func (s *Shell) export(args []string, _ map[string]bool) error {
+	return ExportCatalog(args[0], s.library)
+}
+
+func (s *Shell) recommend(args []string, flags map[string]bool) error {
+	id, err := parseArgID(args[1], args[0]+" ID")
+	if err != nil {
+		return err
+	}
+	var recs []services.Recommendation
+	switch args[0] {
+	case "book":
+		recs, err = s.library.AlsoBorrowed(id, services.DefaultRecommendations)
+	case "member":
+		if err := s.actFor(id); err != nil {
+			return err
+		}
+		recs, err = s.library.RecommendFor(id, services.DefaultRecommendations)
+	default:
+		return fmt.Errorf("usage: %s", shellCommands["recommend"].usage)
+	}
+	if err != nil {
+		return err
+	}
+	books := make([]models.Book, len(recs))
+	for i, rec := range recs {
+		books[i] = rec.Book
+	}
+	return s.printBooks(books, flags["json"])
+}
+
+func (s *Shell) listBranches([]string, map[string]bool) error {
+	for _, branch := range s.library.ListBranches() {
+		fmt.Fprintf(s.out, "ID: %d, Name: %s\n", branch.ID, branch.Name)
+	}
+	return nil
+}
+
+func (s *Shell) addBranch(args []string, _ map[string]bool) error {
+	id, err := parseArgID(args[0], "branch ID")
+	if err != nil {
+		return err
+	}
+	return s.run(concurrency.Command{Kind: concurrency.CommandAddBranch, Branch: id, Name: strings.Join(args[1:], " ")}, "Branch added.")
+}
+
+func (s *Shell) transfer(args []string, _ map[string]bool) error {
+	return s.moveBook(args, concurrency.CommandTransfer, "Book sent.")
+}
+
+func (s *Shell) receive(args []string, _ map[string]bool) error {
+	return s.moveBook(args, concurrency.CommandReceive, "Book received.")
+}
+
+func (s *Shell) moveBook(args []string, kind concurrency.CommandKind, done string) error {
+	bookID, err := parseArgID(args[0], "book ID")
+	if err != nil {
+		return err
+	}
+	branchID, err := parseArgID(args[1], "branch ID")
+	if err != nil {
+		return err
+	}
+	return s.run(concurrency.Command{Kind: kind, BookID: bookID, Branch: branchID}, done)
+}
+
+// run sends a command through the pool and prints done on success.
+func (s *Shell) run(cmd concurrency.Command, done string) error {
+	if err := runCommand(s.pool, cmd); err != nil {
+		return err
+	}
+	fmt.Fprintln(s.out, done)
+	return nil
+}
+
+// bookJSON is the --json representation of a book.
+type bookJSON struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Status      string `json:"status"`
+	ReservedBy  int    `json:"reserved_by,omitempty"`
+	Branch      int    `json:"branch"`
+	Destination int    `json:"destination,omitempty"`
+}
+
+func (s *Shell) printBooks(books []models.Book, asJSON bool) error {
+	if asJSON {
+		out := make([]bookJSON, 0, len(books))
+		for _, b := range books {
+			out = append(out, bookJSON{
+				ID: b.ID, Title: b.Title, Author: b.Author, Status: string(b.Status), ReservedBy: b.ReservedBy,
+				Branch: b.Branch, Destination: b.Destination,
+			})
+		}
+		enc := json.NewEncoder(s.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	if len(books) == 0 {
+		fmt.Fprintln(s.out, "No books.")
+		return nil
+	}
+	for _, b := range books {
+		fmt.Fprintf(s.out, "ID: %d, Title: %s, Author: %s, Status: %s, Branch: %d\n", b.ID, b.Title, b.Author, b.Status, b.Branch)
+	}
+	return nil
+}
+
+func parseArgID(arg string, what string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", what, arg)
+	}
+	return id, nil
+}
+
+func parseBookMember(args []string) (int, int, error) {
+	bookID, err := parseArgID(args[0], "book ID")
+	if err != nil {
+		return 0, 0, err
+	}
+	memberID, err := parseArgID(args[1], "member ID")
+	if err != nil {
+		return 0, 0, err
+	}
+	return bookID, memberID, nil
+}
+
+// loanCommand builds a borrow or return command from
+// "<book-id>[,<book-id>...] <member-id> [branch-id]"; several book IDs make a
+// batch command.
+func loanCommand(args []string, single, batch concurrency.CommandKind) (concurrency.Command, error) {
+	bookIDs, err := parseIDList(args[0])
+	if err != nil {
+		return concurrency.Command{}, fmt.Errorf("invalid book IDs %q", args[0])
+	}
+	memberID, err := parseArgID(args[1], "member ID")
+	if err != nil {
+		return concurrency.Command{}, err
+	}
+	branchID, err := optionalBranch(args, 2)
+	if err != nil {
+		return concurrency.Command{}, err
+	}
+	if len(bookIDs) > 1 {
+		return concurrency.Command{Kind: batch, BookIDs: bookIDs, MemberID: memberID, Branch: branchID}, nil
+	}
+	return concurrency.Command{Kind: single, BookID: bookIDs[0], MemberID: memberID, Branch: branchID}, nil
+}
+
+// optionalBranch parses the branch ID at args[i], returning 0 if it is absent.
+func optionalBranch(args []string, i int) (int, error) {
+	if len(args) <= i {
+		return 0, nil
+	}
+	return parseArgID(args[i], "branch ID")
+}
+
+// tokenize splits a command line on whitespace, keeping double-quoted
+// sections together.
+func tokenize(line string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes, inToken := false, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			inToken = true
+		case !inQuotes && (r == ' ' || r == '\t'):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if inQuotes {
+		return nil, errors.New("unterminated quote")
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"library_management/auth"
+	"library_management/catalog"
+	"library_management/concurrency"
+	"library_management/models"
+	"library_management/services"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	librarian = auth.Account{Username: "admin", Role: auth.RoleLibrarian}
+	alice     = auth.Account{Username: auth.MemberUsername(1), Role: auth.RoleMember, MemberID: 1}
+)
+
+// newTestShell returns a shell logged in as account over a library with
+// members 1 and 2 and books 101 and 102.
+func newTestShell(t *testing.T, account auth.Account) (*Shell, *strings.Builder) {
+	t.Helper()
+	library := services.NewLibrary()
+	t.Cleanup(library.Close)
+	library.AddMember(models.Member{ID: 1, Name: "Alice"})
+	library.AddMember(models.Member{ID: 2, Name: "Bob"})
+	library.AddBook(models.Book{ID: 101, Title: "Go Programming", Author: "John Doe"})
+	library.AddBook(models.Book{ID: 102, Title: "Introducing Go", Author: "Caleb Doxsey"})
+	pool := concurrency.NewCommandPool(library, 2, 4)
+	t.Cleanup(func() { pool.Shutdown(context.Background()) })
+
+	accounts, err := auth.NewStore("")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	var out strings.Builder
+	return NewShell(library, pool, accounts, account, &out), &out
+}
+
+func TestShellPermissions(t *testing.T) {
+	tests := []struct {
+		line   string
+		member error // Result when run as member 1
+	}{
+		{"borrow 101 1", nil},
+		{"borrow 102 2", errOtherMember},
+		{"return 101,102 2", errOtherMember},
+		{"reserve 102 2", errOtherMember},
+		{"list borrowed 2", errOtherMember},
+		{"list borrowed 1", nil},
+		{"recommend member 2", errOtherMember},
+		{"search go", nil},
+		{"add-book 103 Title", errLibrarianOnly},
+		{"remove-member 2", errLibrarianOnly},
+		{"status 102 lost", errLibrarianOnly},
+		{"check --repair", errLibrarianOnly},
+		{"import books missing.csv", errLibrarianOnly},
+		{"transfer 102 1", errLibrarianOnly},
+	}
+
+	member, _ := newTestShell(t, alice)
+	for _, tt := range tests {
+		if err := member.Execute(tt.line); !errors.Is(err, tt.member) {
+			t.Errorf("member: %q = %v, want %v", tt.line, err, tt.member)
+		}
+	}
+
+	// The table's commands change the library, so only check that the
+	// librarian gets past the permission checks, then act for a member on a
+	// fresh library.
+	admin, _ := newTestShell(t, librarian)
+	for _, tt := range tests {
+		err := admin.Execute(tt.line)
+		if errors.Is(err, errOtherMember) || errors.Is(err, errLibrarianOnly) {
+			t.Errorf("librarian: %q refused: %v", tt.line, err)
+		}
+	}
+	admin, _ = newTestShell(t, librarian)
+	for _, line := range []string{"borrow 102 2", "return 102 2", "add-book 104 Title"} {
+		if err := admin.Execute(line); err != nil {
+			t.Errorf("librarian: %q = %v", line, err)
+		}
+	}
+}
+
+func TestShellHelpHidesLibrarianCommands(t *testing.T) {
+	member, out := newTestShell(t, alice)
+	if err := member.Execute("help"); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	if !strings.Contains(out.String(), "borrow <book-id>") {
+		t.Errorf("help does not list borrow:\n%s", out)
+	}
+	if strings.Contains(out.String(), "add-book") {
+		t.Errorf("help lists a librarian command to a member:\n%s", out)
+	}
+	if err := member.Execute("help add-book"); err == nil {
+		t.Error("help add-book succeeded for a member")
+	}
+}
+
+func TestShellMemberLogins(t *testing.T) {
+	admin, _ := newTestShell(t, librarian)
+	for _, line := range []string{`add-member 3 "Carol Smith" 4321`, "add-member 4 Dave"} {
+		if err := admin.Execute(line); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+	}
+	if err := admin.Execute("add-member 5 Eve 12"); !errors.Is(err, auth.ErrWeakPIN) {
+		t.Errorf("add-member with a short PIN = %v, want %v", err, auth.ErrWeakPIN)
+	}
+
+	account, err := admin.accounts.Login(auth.MemberUsername(3), "4321")
+	if err != nil || account.MemberID != 3 {
+		t.Fatalf("Login of the added member = %+v, %v", account, err)
+	}
+	if member, _ := admin.library.GetMember(3); member.Name != "Carol Smith" {
+		t.Errorf("member name = %q, want %q", member.Name, "Carol Smith")
+	}
+
+	if err := admin.Execute("remove-member 3"); err != nil {
+		t.Fatalf("remove-member: %v", err)
+	}
+	if _, err := admin.accounts.Login(auth.MemberUsername(3), "4321"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Login of a removed member = %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+}
+
+func TestShellImportReportsRejectedRows(t *testing.T) {
+	admin, _ := newTestShell(t, librarian)
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.csv")
+	mixed := filepath.Join(dir, "mixed.csv")
+	os.WriteFile(valid, []byte("id,name\n3,Carol\n"), 0o644)
+	// Member 2 already exists and the third row has no name.
+	os.WriteFile(mixed, []byte("id,name\n4,Dave\n2,Bob\n5,\n"), 0o644)
+
+	if err := admin.Execute("import members " + valid); err != nil {
+		t.Fatalf("import of valid rows: %v", err)
+	}
+	if err := admin.Execute("import members " + mixed); !errors.Is(err, catalog.ErrRowsRejected) {
+		t.Fatalf("import with rejected rows = %v, want %v", err, catalog.ErrRowsRejected)
+	}
+	for _, id := range []int{3, 4} {
+		if _, err := admin.library.GetMember(id); err != nil {
+			t.Errorf("member %d was not imported: %v", id, err)
+		}
+	}
+}
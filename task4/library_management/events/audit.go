@@ -0,0 +1,100 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditLog is an append-only JSON-lines file of library events.
+type AuditLog struct {
+	path string
+	file *os.File
+	mu   sync.Mutex // Serializes writes and queries
+}
+
+// Filter selects audit entries. Zero fields match everything.
+type Filter struct {
+	MemberID int
+	BookID   int
+	Type     Type
+	From     time.Time // Only events at or after From
+	To       time.Time // Only events before To
+}
+
+// Matches reports whether e passes the filter.
+func (f Filter) Matches(e Event) bool {
+	return (f.MemberID == 0 || e.MemberID == f.MemberID) &&
+		(f.BookID == 0 || e.BookID == f.BookID) &&
+		(f.Type == "" || e.Type == f.Type) &&
+		(f.From.IsZero() || !e.Time.Before(f.From)) &&
+		(f.To.IsZero() || e.Time.Before(f.To))
+}
+
+// NewAuditLog opens (or creates) the audit log at path for appending.
+func NewAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{path: path, file: file}, nil
+}
+
+// Record appends a single event to the log.
+func (a *AuditLog) Record(e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err = a.file.Write(append(line, '\n'))
+	return err
+}
+
+// Consume records every event received from ch until it is closed.
+// It is meant to be run in its own goroutine with a channel from Bus.Subscribe.
+func (a *AuditLog) Consume(ch <-chan Event) error {
+	var errs []error
+	for e := range ch {
+		if err := a.Record(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Query returns the logged events matching the filter, oldest first.
+func (a *AuditLog) Query(filter Filter) ([]Event, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	file, err := os.Open(a.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	matches := []Event{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		if filter.Matches(e) {
+			matches = append(matches, e)
+		}
+	}
+	return matches, scanner.Err()
+}
+
+// Close closes the underlying file.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAuditLogConsumeAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := NewAuditLog(path)
+	if err != nil {
+		t.Fatalf("NewAuditLog: %v", err)
+	}
+
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return day.Add(time.Duration(hours) * time.Hour) }
+	evts := []Event{
+		{Type: BookReserved, BookID: 101, MemberID: 1, Time: at(0)},
+		{Type: BookBorrowed, BookID: 101, MemberID: 1, Time: at(1)},
+		{Type: BookBorrowed, BookID: 102, MemberID: 2, Time: at(2)},
+		{Type: BookStatusChanged, BookID: 103, Status: "Lost", Time: at(3)},
+		{Type: TransferStarted, BookID: 103, Branch: 2, Time: at(4)},
+		{Type: BookReturned, BookID: 101, MemberID: 1, Time: at(5)},
+	}
+
+	bus := NewBus()
+	ch, _ := bus.Subscribe()
+	done := make(chan error)
+	go func() { done <- audit.Consume(ch) }()
+	for _, e := range evts {
+		bus.Publish(e)
+	}
+	bus.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []Event
+	}{
+		{"everything", Filter{}, evts},
+		{"member", Filter{MemberID: 1}, []Event{evts[0], evts[1], evts[5]}},
+		{"book", Filter{BookID: 103}, []Event{evts[3], evts[4]}},
+		{"type", Filter{Type: BookBorrowed}, []Event{evts[1], evts[2]}},
+		{"member and type", Filter{MemberID: 1, Type: BookBorrowed}, []Event{evts[1]}},
+		// From is inclusive and To exclusive.
+		{"time range", Filter{From: at(1), To: at(4)}, []Event{evts[1], evts[2], evts[3]}},
+		{"from", Filter{From: at(4)}, []Event{evts[4], evts[5]}},
+		{"no match", Filter{BookID: 101, Type: TransferStarted}, []Event{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := audit.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Query(%+v) = %+v, want %+v", tt.filter, got, tt.want)
+			}
+		})
+	}
+
+	// Events survive reopening the log, and new ones are appended.
+	if err := audit.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	reopened, err := NewAuditLog(path)
+	if err != nil {
+		t.Fatalf("NewAuditLog: %v", err)
+	}
+	defer reopened.Close()
+	extra := Event{Type: BookReturned, BookID: 102, MemberID: 2, Time: at(6)}
+	if err := reopened.Record(extra); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	got, err := reopened.Query(Filter{MemberID: 2})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if want := []Event{evts[2], extra}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after reopening = %+v, want %+v", got, want)
+	}
+}
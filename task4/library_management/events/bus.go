@@ -0,0 +1,124 @@
+package events
+
+import "sync"
+
+// Bus fans published events out to any number of subscribers.
+// Publish never blocks: each subscriber has its own queue that is drained
+// into its channel in publish order, so a slow subscriber cannot stall the
+// library (which publishes while holding its lock).
+type Bus struct {
+	subs   map[int]*subscription // Keyed by subscription ID
+	nextID int
+	closed bool
+	mu     sync.Mutex // Protects subs, nextID and closed
+}
+
+// subscription buffers events for one subscriber.
+type subscription struct {
+	out    chan Event
+	queue  []Event
+	done   bool
+	mu     sync.Mutex
+	notify *sync.Cond
+}
+
+// NewBus creates an empty Bus.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[int]*subscription)}
+}
+
+// Subscribe registers a new subscriber. It returns the channel events are
+// delivered on and a function that cancels the subscription; the channel
+// is closed once cancelled or when the bus is closed.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &subscription{out: make(chan Event)}
+	s.notify = sync.NewCond(&s.mu)
+	if b.closed {
+		close(s.out)
+		return s.out, func() {}
+	}
+
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = s
+	go s.run()
+
+	var once sync.Once
+	return s.out, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			s.stop(false)
+		})
+	}
+}
+
+// Publish delivers an event to every current subscriber.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, s := range b.subs {
+		s.push(e)
+	}
+}
+
+// Close stops the bus. Subscribers receive any queued events and then see
+// their channels closed.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for id, s := range b.subs {
+		delete(b.subs, id)
+		s.stop(true)
+	}
+}
+
+func (s *subscription) push(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.queue = append(s.queue, e)
+	s.notify.Signal()
+}
+
+// stop ends the subscription. Queued events are still delivered when
+// drain is true and discarded otherwise.
+func (s *subscription) stop(drain bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	if !drain {
+		s.queue = nil
+	}
+	s.notify.Signal()
+}
+
+// run forwards queued events to the subscriber until stopped.
+func (s *subscription) run() {
+	defer close(s.out)
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.done {
+			s.notify.Wait()
+		}
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		e := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		s.out <- e
+	}
+}
@@ -0,0 +1,123 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+// receive returns the next event from ch, failing the test if none arrives.
+func receive(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed, want an event")
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+	}
+	return Event{}
+}
+
+// waitClosed fails the test unless ch is closed without delivering more events.
+func waitClosed(t *testing.T, ch <-chan Event) {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if ok {
+			t.Fatalf("got %+v, want the channel closed", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed")
+	}
+}
+
+func TestBusDeliversInOrderToEverySubscriber(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+	first, _ := bus.Subscribe()
+	second, _ := bus.Subscribe()
+
+	const n = 100
+	for i := 1; i <= n; i++ {
+		bus.Publish(Event{Type: BookBorrowed, BookID: i})
+	}
+	for _, ch := range []<-chan Event{first, second} {
+		for i := 1; i <= n; i++ {
+			if e := receive(t, ch); e.BookID != i {
+				t.Fatalf("event %d is for book %d, want %d", i, e.BookID, i)
+			}
+		}
+	}
+}
+
+func TestBusCloseDrainsThenCloses(t *testing.T) {
+	bus := NewBus()
+	ch, _ := bus.Subscribe()
+	for i := 1; i <= 3; i++ {
+		bus.Publish(Event{Type: BookReturned, BookID: i})
+	}
+	bus.Close()
+	bus.Publish(Event{Type: BookReturned, BookID: 4}) // Dropped: the bus is closed
+
+	for i := 1; i <= 3; i++ {
+		if e := receive(t, ch); e.BookID != i {
+			t.Fatalf("event %d is for book %d, want %d", i, e.BookID, i)
+		}
+	}
+	waitClosed(t, ch)
+
+	late, _ := bus.Subscribe()
+	waitClosed(t, late)
+}
+
+func TestBusCancelStopsDelivery(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+	ch, cancel := bus.Subscribe()
+	bus.Publish(Event{Type: BookReserved, BookID: 1})
+	cancel()
+	cancel() // Safe to call twice
+	bus.Publish(Event{Type: BookReserved, BookID: 2})
+
+	// The event queued before cancelling may or may not be delivered, but
+	// nothing after it is.
+	for e := range ch {
+		if e.BookID != 1 {
+			t.Fatalf("got %+v after cancelling", e)
+		}
+	}
+}
+
+func TestBusSlowSubscriberDoesNotBlockPublishers(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+	slow, _ := bus.Subscribe() // Never read until every event is published
+	fast, _ := bus.Subscribe()
+
+	const n = 1000
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for i := 1; i <= n; i++ {
+			bus.Publish(Event{Type: BookBorrowed, BookID: i})
+		}
+	}()
+	for i := 1; i <= n; i++ {
+		if e := receive(t, fast); e.BookID != i {
+			t.Fatalf("fast subscriber got book %d, want %d", e.BookID, i)
+		}
+	}
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a subscriber that is not reading")
+	}
+
+	for i := 1; i <= n; i++ {
+		if e := receive(t, slow); e.BookID != i {
+			t.Fatalf("slow subscriber got book %d, want %d", e.BookID, i)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package events
+
+import "time"
+
+// Type identifies what happened in the library.
+type Type string
+
+const (
+	BookBorrowed         Type = "book_borrowed"
+	BookReturned         Type = "book_returned"
+	BookReserved         Type = "book_reserved"
+	ReservationExpired   Type = "reservation_expired"   // Reservation auto-cancelled because the book was not borrowed in time
+	ReservationCancelled Type = "reservation_cancelled" // Reservation dropped because its member was removed or the book's status changed
+	BookStatusChanged    Type = "book_status_changed"   // A librarian moved the book to a new lifecycle state
+	TransferStarted      Type = "transfer_started"      // The book left its branch for another one
+	TransferCompleted    Type = "transfer_completed"    // The book was received at its destination branch
+)
+
+// Event is a single entry in the library's event stream.
+type Event struct {
+	Type     Type      `json:"type"`
+	BookID   int       `json:"book_id"`
+	MemberID int       `json:"member_id"`
+	Status   string    `json:"status,omitempty"` // New status, for BookStatusChanged
+	Branch   int       `json:"branch,omitempty"` // Destination or receiving branch, for transfers
+	Time     time.Time `json:"time"`
+}
@@ -1,41 +1,284 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"library_management/api"
+	"library_management/auth"
+	"library_management/concurrency"
 	"library_management/controllers"
+	"library_management/events"
+	"library_management/metrics"
 	"library_management/models"
+	"library_management/notifications"
+	"library_management/rpc"
 	"library_management/services"
-	"library_management/concurrency"
+	"library_management/simulation"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
 )
 
+// passwordEnv names the environment variable holding the password or PIN of
+// the -user account, so it stays out of scripts and the process list.
+const passwordEnv = "LIBRARY_PASSWORD"
+
 func main() {
+	os.Exit(run())
+}
+
+// run starts the library in the mode selected by the flags and returns the
+// process exit code.
+func run() int {
+	auditPath := flag.String("audit", "library_audit.jsonl", "path of the append-only audit log")
+	httpAddr := flag.String("http", "", "serve the REST API on this address (e.g. :8080) instead of the console")
+	outboxPath := flag.String("outbox", "notifications_outbox.jsonl", "path of the member notification outbox")
+	prefsPath := flag.String("preferences", "notification_preferences.json", "path of the members' notification preferences")
+	accountsPath := flag.String("accounts", "library_accounts.json", "path of the console login accounts (bcrypt-hashed)")
+	workers := flag.Int("workers", 4, "number of command workers")
+	queueSize := flag.Int("queue", 16, "pending commands buffered per worker")
+	journalPath := flag.String("journal", "", "append every executed command to this journal")
+	replayPath := flag.String("replay", "", "replay the commands of this journal on startup")
+	importBooks := flag.String("import-books", "", "import books from a .csv or .json file on startup")
+	importMembers := flag.String("import-members", "", "import members from a .csv or .json file on startup")
+	shellMode := flag.Bool("shell", false, "use the command shell instead of the numbered menu")
+	rpcMode := flag.Bool("rpc", false, "speak line-delimited JSON-RPC 2.0 on stdin/stdout instead of the console")
+	scriptPath := flag.String("script", "", "run the shell commands in this file and exit; exits non-zero on the first failure")
+	scriptUser := flag.String("user", "", "account a -script runs as; its password or PIN is read from $"+passwordEnv)
+	exportPath := flag.String("export", "", "export the catalog and loans to a .csv or .json file and exit")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 5*time.Second, "how long to wait for queued requests on exit")
+	simulate := flag.Bool("simulate", false, "run the concurrent load simulator against a fresh library and exit")
+	simMembers := flag.Int("sim-members", 20, "virtual members in the simulation")
+	simBooks := flag.Int("sim-books", 10, "books in the simulated catalog")
+	simDuration := flag.Duration("sim-duration", 10*time.Second, "how long the simulation runs")
+	simSeed := flag.Int64("sim-seed", 0, "random seed for the simulation (0 picks one)")
+	metricsAddr := flag.String("metrics", "", "serve Prometheus metrics at /metrics on this address (e.g. 127.0.0.1:9090)")
+	flag.Parse()
+
+	// The simulator uses its own library, so it runs before anything else
+	// is opened.
+	if *simulate {
+		result := simulation.Run(context.Background(), simulation.Config{
+			Members:   *simMembers,
+			Books:     *simBooks,
+			Duration:  *simDuration,
+			Workers:   *workers,
+			QueueSize: *queueSize,
+			Seed:      *simSeed,
+		})
+		result.WriteText(os.Stdout)
+		if !result.OK() {
+			return 1
+		}
+		return 0
+	}
+
+	// In JSON-RPC mode stdout carries only responses, so status messages
+	// printed while starting up go to stderr instead.
+	rpcOut := os.Stdout
+	if *rpcMode {
+		os.Stdout = os.Stderr
+	}
+
 	// Initialize the library.
 	library := services.NewLibrary()
 
+	// Record every library event in the audit log.
+	audit, err := events.NewAuditLog(*auditPath)
+	if err != nil {
+		log.Fatalf("could not open audit log: %v", err)
+	}
+	defer audit.Close()
+	// Recommendations draw on every borrow the audit log has seen.
+	past, err := audit.Query(events.Filter{})
+	if err != nil {
+		log.Fatalf("could not read audit log: %v", err)
+	}
+	library.LoadHistory(past)
+	auditEvents, _ := library.Subscribe()
+	auditDone := make(chan struct{})
+	go func() {
+		defer close(auditDone)
+		if err := audit.Consume(auditEvents); err != nil {
+			log.Printf("audit log: %v", err)
+		}
+	}()
+
+	// Queue member notifications in the outbox and remind members about due dates.
+	outbox, err := notifications.NewOutbox(*outboxPath)
+	if err != nil {
+		log.Fatalf("could not open notification outbox: %v", err)
+	}
+	defer outbox.Close()
+	library.SetNotifier(outbox)
+	prefs, err := notifications.OpenPreferences(*prefsPath)
+	if err != nil {
+		log.Fatalf("could not load notification preferences: %v", err)
+	}
+	library.SetPreferences(prefs)
+	stopDueDateMonitor := library.StartDueDateMonitor(time.Minute)
+	defer stopDueDateMonitor()
+
+	// The console, shell, scripts and REST API share the login accounts.
+	accounts, err := auth.NewStore(*accountsPath)
+	if err != nil {
+		log.Fatalf("could not load accounts: %v", err)
+	}
+
+	// Tell the console user when a reservation times out.
+	expiryEvents, _ := library.Subscribe()
+	go func() {
+		for e := range expiryEvents {
+			if e.Type == events.ReservationExpired {
+				fmt.Printf("Auto-cancellation: Reservation for book %d by member %d has timed out.\n", e.BookID, e.MemberID)
+			}
+		}
+	}()
+
 	// Add an initial member for testing.
-	library.AddMember(models.Member{
-		ID:            1,
-		Name:          "Alice",
-		BorrowedBooks: []models.Book{},
-	})
+	if err := library.AddMember(models.Member{
+		ID:    1,
+		Name:  "Alice",
+		Loans: []models.Loan{},
+	}); err != nil {
+		log.Fatalf("could not add sample member: %v", err)
+	}
 
 	// Add some sample books.
-	library.AddBook(models.Book{
-		ID:     101,
-		Title:  "The Go Programming Language",
-		Author: "Alan A. A. Donovan",
-	})
-	library.AddBook(models.Book{
-		ID:     102,
-		Title:  "Introducing Go",
-		Author: "Caleb Doxsey",
-	})
-
-	// Create a channel for reservation requests.
-	reservationChan := make(chan concurrency.ReservationRequest)
-
-	// Start the reservation worker that listens on the channel.
-	concurrency.StartReservationWorker(library, reservationChan)
-
-	// Start the console-based library controller.
-	controllers.LibraryController(library, reservationChan)
+	sampleBooks := []models.Book{
+		{ID: 101, Title: "The Go Programming Language", Author: "Alan A. A. Donovan"},
+		{ID: 102, Title: "Introducing Go", Author: "Caleb Doxsey"},
+	}
+	for _, book := range sampleBooks {
+		if err := library.AddBook(book); err != nil {
+			log.Fatalf("could not add sample book %d: %v", book.ID, err)
+		}
+	}
+
+	// Rebuild state from a previous run's journal.
+	if *replayPath != "" {
+		entries, err := concurrency.ReadJournal(*replayPath)
+		if err != nil {
+			log.Fatalf("could not read journal: %v", err)
+		}
+		applied, errs := concurrency.Replay(library, entries)
+		for _, err := range errs {
+			log.Printf("replay: %v", err)
+		}
+		fmt.Printf("Replayed %d commands from %s.\n", applied, *replayPath)
+	}
+
+	// Start the command workers; every mutation goes through them.
+	var observers []concurrency.Observer
+	if *journalPath != "" {
+		journal, err := concurrency.NewJournal(*journalPath)
+		if err != nil {
+			log.Fatalf("could not open journal: %v", err)
+		}
+		defer journal.Close()
+		observers = append(observers, journal.Observe)
+	}
+	pool := concurrency.NewCommandPool(library, *workers, *queueSize, observers...)
+
+	// Expose operation counters for scraping, whatever the front end.
+	if *metricsAddr != "" {
+		registry := metrics.NewRegistry()
+		library.RegisterMetrics(registry)
+		pool.RegisterMetrics(registry)
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", registry)
+		metricsSrv := &http.Server{Addr: *metricsAddr, Handler: mux}
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("metrics server: %v", err)
+			}
+		}()
+		defer metricsSrv.Close()
+	}
+
+	// Bulk-load members and books.
+	if *importMembers != "" {
+		if err := controllers.ImportMembers(*importMembers, pool); err != nil {
+			log.Printf("import members: %v", err)
+		}
+	}
+	if *importBooks != "" {
+		if err := controllers.ImportBooks(*importBooks, library, pool); err != nil {
+			log.Printf("import books: %v", err)
+		}
+	}
+
+	exitCode := 0
+	if *exportPath != "" {
+		// Export the catalog without starting a console or server.
+		if err := controllers.ExportCatalog(*exportPath, library); err != nil {
+			log.Printf("export: %v", err)
+			exitCode = 1
+		}
+	} else if *httpAddr != "" {
+		// Serve the REST API for the web front desk until interrupted.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		srv := &http.Server{Addr: *httpAddr, Handler: api.SetupRouter(library, pool, accounts)}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("http server: %v", err)
+				stop()
+			}
+		}()
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("http shutdown: %v", err)
+		}
+	} else if *rpcMode {
+		// Answer JSON-RPC requests until stdin is closed or interrupted.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if err := rpc.NewServer(library, pool).Serve(ctx, os.Stdin, rpcOut); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("rpc: %v", err)
+			exitCode = 1
+		}
+	} else if *scriptPath != "" {
+		// Run a shell script non-interactively as the -user account.
+		account, err := scriptAccount(accounts, *scriptUser)
+		if err == nil {
+			err = controllers.NewShell(library, pool, accounts, account, os.Stdout).RunScript(*scriptPath)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			exitCode = 1
+		}
+	} else if *shellMode {
+		// Start the command shell behind the console login.
+		controllers.ShellController(library, pool, accounts)
+	} else {
+		// Start the console-based library controller behind a login.
+		controllers.LibraryController(library, pool, audit, accounts)
+	}
+
+	// Drain queued commands, then flush pending events to the audit log.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := pool.Shutdown(shutdownCtx); err != nil {
+		log.Printf("command pool: %v", err)
+	}
+	library.Close()
+	<-auditDone
+	return exitCode
+}
+
+// scriptAccount logs in the account a script runs as, taking its password or
+// PIN from the environment.
+func scriptAccount(accounts *auth.Store, username string) (auth.Account, error) {
+	if username == "" {
+		return auth.Account{}, fmt.Errorf("-script needs -user and $%s to log in", passwordEnv)
+	}
+	return accounts.Login(username, os.Getenv(passwordEnv))
 }
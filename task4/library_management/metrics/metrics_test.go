@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryWritesPrometheusText(t *testing.T) {
+	r := NewRegistry()
+	borrows := NewCounter("borrows_total", "Books lent.")
+	outcomes := NewCounterVec("reservations_total", "Reservations by outcome.", "outcome", "placed", "expired")
+	wait := NewHistogram("wait_seconds", "Lock wait.", 0.1, 1)
+	r.Register(borrows, outcomes, wait, NewGaugeFunc("queue_depth", "Queued.", func() float64 { return 3 }))
+
+	borrows.Inc()
+	borrows.Inc()
+	outcomes.Inc("placed")
+	wait.Observe(0.05)
+	wait.Observe(0.5)
+	wait.Observe(2)
+
+	var out strings.Builder
+	r.WriteText(&out)
+	want := `# HELP borrows_total Books lent.
+# TYPE borrows_total counter
+borrows_total 2
+# HELP reservations_total Reservations by outcome.
+# TYPE reservations_total counter
+reservations_total{outcome="expired"} 0
+reservations_total{outcome="placed"} 1
+# HELP wait_seconds Lock wait.
+# TYPE wait_seconds histogram
+wait_seconds_bucket{le="0.1"} 1
+wait_seconds_bucket{le="1"} 2
+wait_seconds_bucket{le="+Inf"} 3
+wait_seconds_sum 2.55
+wait_seconds_count 3
+# HELP queue_depth Queued.
+# TYPE queue_depth gauge
+queue_depth 3
+`
+	if out.String() != want {
+		t.Fatalf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
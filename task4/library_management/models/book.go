@@ -2,9 +2,12 @@ package models
 
 // Book represents a library book.
 type Book struct {
-	ID         int
-	Title      string
-	Author     string
-	Status     string // "Available", "Reserved", or "Borrowed"
-	ReservedBy int    // ID of the member who reserved the book (0 if not reserved)
+	ID          int
+	Title       string
+	Author      string
+	Status      BookStatus // Lifecycle state, see BookStatus
+	ReservedBy  int        // ID of the member who reserved the book (0 if not reserved)
+	HomeBranch  int        // Branch that owns the copy
+	Branch      int        // Branch where the copy currently is (its origin while in transit)
+	Destination int        // Branch the copy is being sent to (0 unless In Transit)
 }
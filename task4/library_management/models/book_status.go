@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BookStatus is the lifecycle state of a book.
+type BookStatus string
+
+const (
+	StatusAvailable BookStatus = "Available"
+	StatusReserved  BookStatus = "Reserved"
+	StatusBorrowed  BookStatus = "Borrowed"
+	StatusLost      BookStatus = "Lost"
+	StatusDamaged   BookStatus = "Damaged"
+	StatusInRepair  BookStatus = "In Repair"
+	StatusWithdrawn BookStatus = "Withdrawn"
+	StatusInTransit BookStatus = "In Transit" // Being moved between branches
+)
+
+// Statuses lists every book status.
+var Statuses = []BookStatus{
+	StatusAvailable, StatusReserved, StatusBorrowed,
+	StatusLost, StatusDamaged, StatusInRepair, StatusWithdrawn, StatusInTransit,
+}
+
+// Errors describing invalid statuses. Use errors.Is to match them.
+var (
+	ErrInvalidTransition = errors.New("invalid book status transition")
+	ErrUnknownStatus     = errors.New("unknown book status")
+)
+
+// transitions lists the states a book may move to from each state.
+// Withdrawn is terminal.
+var transitions = map[BookStatus][]BookStatus{
+	StatusAvailable: {StatusReserved, StatusBorrowed, StatusLost, StatusDamaged, StatusWithdrawn, StatusInTransit},
+	StatusReserved:  {StatusAvailable, StatusBorrowed, StatusLost, StatusDamaged},
+	StatusBorrowed:  {StatusAvailable, StatusLost, StatusDamaged, StatusInTransit},
+	StatusLost:      {StatusAvailable, StatusWithdrawn},
+	StatusDamaged:   {StatusAvailable, StatusInRepair, StatusWithdrawn},
+	StatusInRepair:  {StatusAvailable, StatusWithdrawn},
+	StatusWithdrawn: {},
+	StatusInTransit: {StatusAvailable, StatusReserved, StatusLost, StatusDamaged},
+}
+
+// TransitionError reports a status change the lifecycle does not allow.
+// It matches ErrInvalidTransition with errors.Is.
+type TransitionError struct {
+	From BookStatus
+	To   BookStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change book status from %s to %s", e.From, e.To)
+}
+
+// Is makes errors.Is(err, ErrInvalidTransition) true for a TransitionError.
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// CanTransitionTo reports whether the lifecycle allows moving from s to next.
+func (s BookStatus) CanTransitionTo(next BookStatus) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns a *TransitionError if s cannot move to next.
+func (s BookStatus) ValidateTransition(next BookStatus) error {
+	if !s.CanTransitionTo(next) {
+		return &TransitionError{From: s, To: next}
+	}
+	return nil
+}
+
+// ParseBookStatus converts user input such as "in repair", "IN_REPAIR" or
+// "lost" to a BookStatus.
+func ParseBookStatus(s string) (BookStatus, error) {
+	normalized := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(s))
+	for _, status := range Statuses {
+		if strings.ReplaceAll(strings.ToLower(string(status)), " ", "") == normalized {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownStatus, s)
+}
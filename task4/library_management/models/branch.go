@@ -0,0 +1,8 @@
+package models
+
+// Branch is a library location. Every book copy belongs to a home branch and
+// is shelved at one branch at a time.
+type Branch struct {
+	ID   int
+	Name string
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Loan records that a member has borrowed a book. It references the book by
+// ID, so the book's current state is always read from the library.
+type Loan struct {
+	BookID     int
+	MemberID   int
+	BorrowedAt time.Time
+	DueDate    time.Time // When the book must be returned
+}
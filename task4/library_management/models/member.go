@@ -2,8 +2,8 @@ package models
 
 // Member represents a library member.
 type Member struct {
-	ID            int
-	Name          string
-	BorrowedBooks []Book // a slice to hold borrowed books
+	ID        int
+	Name      string
+	Loans     []Loan // Books the member currently has on loan
+	Suspended bool   // Suspended members cannot borrow or reserve
 }
-
@@ -0,0 +1,71 @@
+package notifications
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Kind identifies the reason a member is being notified.
+type Kind string
+
+const (
+	ReservationReady     Kind = "reservation_ready"     // A reserved book is being held for the member
+	ReservationExpired   Kind = "reservation_expired"   // The hold timed out before the book was borrowed
+	ReservationCancelled Kind = "reservation_cancelled" // A librarian changed the reserved book's status
+	DueSoon              Kind = "due_soon"              // A borrowed book is due shortly
+	Overdue              Kind = "overdue"               // A borrowed book is past its due date
+)
+
+// Kinds lists every notification kind, in the order shown to members.
+var Kinds = []Kind{ReservationReady, ReservationExpired, ReservationCancelled, DueSoon, Overdue}
+
+// Message is a notification addressed to a single member.
+type Message struct {
+	MemberID int       `json:"member_id"`
+	Kind     Kind      `json:"kind"`
+	BookID   int       `json:"book_id"`
+	Text     string    `json:"text"`
+	Time     time.Time `json:"time"`
+}
+
+// Notifier delivers messages to members.
+type Notifier interface {
+	Notify(msg Message) error
+}
+
+// Outbox is a Notifier that appends every message as a JSON line to a file,
+// from where a separate process can pick them up and deliver them.
+type Outbox struct {
+	file *os.File
+	mu   sync.Mutex // Serializes writes
+}
+
+// NewOutbox opens (or creates) the outbox file at path for appending.
+func NewOutbox(path string) (*Outbox, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Outbox{file: file}, nil
+}
+
+// Notify appends the message to the outbox file.
+func (o *Outbox) Notify(msg Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, err = o.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the outbox file.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.file.Close()
+}
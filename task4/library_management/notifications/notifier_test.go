@@ -0,0 +1,94 @@
+package notifications
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readOutbox decodes every line of the outbox file at path.
+func readOutbox(t *testing.T, path string) []Message {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open outbox: %v", err)
+	}
+	defer file.Close()
+
+	var messages []Message
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("outbox line %q: %v", scanner.Text(), err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read outbox: %v", err)
+	}
+	return messages
+}
+
+func TestOutboxAppendsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	first := []Message{
+		{MemberID: 1, Kind: ReservationReady, BookID: 101, Text: "ready", Time: at},
+		{MemberID: 2, Kind: DueSoon, BookID: 102, Text: "due soon", Time: at.Add(time.Minute)},
+	}
+	second := Message{MemberID: 1, Kind: Overdue, BookID: 101, Text: "overdue", Time: at.Add(time.Hour)}
+
+	outbox, err := NewOutbox(path)
+	if err != nil {
+		t.Fatalf("NewOutbox: %v", err)
+	}
+	for _, msg := range first {
+		if err := outbox.Notify(msg); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+	if err := outbox.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Reopening appends rather than truncating.
+	outbox, err = NewOutbox(path)
+	if err != nil {
+		t.Fatalf("NewOutbox after reopen: %v", err)
+	}
+	if err := outbox.Notify(second); err != nil {
+		t.Fatalf("Notify after reopen: %v", err)
+	}
+	if err := outbox.Close(); err != nil {
+		t.Fatalf("Close after reopen: %v", err)
+	}
+
+	got := readOutbox(t, path)
+	want := append(first, second)
+	if len(got) != len(want) {
+		t.Fatalf("outbox has %d messages, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestOutboxNotifyAfterCloseFails(t *testing.T) {
+	outbox, err := NewOutbox(filepath.Join(t.TempDir(), "outbox.jsonl"))
+	if err != nil {
+		t.Fatalf("NewOutbox: %v", err)
+	}
+	if err := outbox.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := outbox.Notify(Message{MemberID: 1, Kind: DueSoon}); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Notify after Close = %v, want %v", err, os.ErrClosed)
+	}
+}
@@ -0,0 +1,106 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Preferences records which kinds of notification each member wants.
+// Every kind is enabled unless a member has opted out of it. Preferences
+// opened with a path are saved to a JSON file after every change.
+type Preferences struct {
+	path    string
+	optOuts map[int]map[Kind]bool // member ID -> kinds the member opted out of
+	mu      sync.RWMutex          // Protects optOuts and the file
+}
+
+// NewPreferences creates Preferences with everything enabled, kept in memory only.
+func NewPreferences() *Preferences {
+	return &Preferences{optOuts: make(map[int]map[Kind]bool)}
+}
+
+// OpenPreferences loads the preferences saved at path. A missing file has
+// everything enabled; an empty path keeps preferences in memory only.
+func OpenPreferences(path string) (*Preferences, error) {
+	p := NewPreferences()
+	p.path = path
+	if path == "" {
+		return p, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved map[int][]Kind
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	for memberID, kinds := range saved {
+		for _, kind := range kinds {
+			p.optOuts[memberID] = setKind(p.optOuts[memberID], kind)
+		}
+	}
+	return p, nil
+}
+
+// Set enables or disables a kind of notification for a member. The change
+// is kept even if saving it fails; the error reports that it was not saved.
+func (p *Preferences) Set(memberID int, kind Kind, enabled bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if enabled {
+		delete(p.optOuts[memberID], kind)
+		if len(p.optOuts[memberID]) == 0 {
+			delete(p.optOuts, memberID)
+		}
+	} else {
+		p.optOuts[memberID] = setKind(p.optOuts[memberID], kind)
+	}
+	return p.save()
+}
+
+// Allows reports whether a member wants notifications of the given kind.
+func (p *Preferences) Allows(memberID int, kind Kind) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return !p.optOuts[memberID][kind]
+}
+
+// save writes every member's opt-outs to the file, as a JSON object mapping
+// member IDs to the kinds they opted out of. Callers hold p.mu.
+func (p *Preferences) save() error {
+	if p.path == "" {
+		return nil
+	}
+	saved := make(map[int][]Kind, len(p.optOuts))
+	for memberID, kinds := range p.optOuts {
+		for kind := range kinds {
+			saved[memberID] = append(saved[memberID], kind)
+		}
+		sort.Slice(saved[memberID], func(i, j int) bool { return saved[memberID][i] < saved[memberID][j] })
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
+
+func setKind(kinds map[Kind]bool, kind Kind) map[Kind]bool {
+	if kinds == nil {
+		kinds = make(map[Kind]bool)
+	}
+	kinds[kind] = true
+	return kinds
+}
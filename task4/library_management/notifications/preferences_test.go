@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreferencesSurviveReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+	prefs, err := OpenPreferences(path)
+	if err != nil {
+		t.Fatalf("OpenPreferences of a missing file: %v", err)
+	}
+	if !prefs.Allows(1, DueSoon) {
+		t.Fatal("a new member has DueSoon disabled")
+	}
+	for _, set := range []struct {
+		member  int
+		kind    Kind
+		enabled bool
+	}{
+		{1, DueSoon, false},
+		{1, Overdue, false},
+		{2, ReservationReady, false},
+		{2, ReservationReady, true},
+	} {
+		if err := prefs.Set(set.member, set.kind, set.enabled); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+
+	reopened, err := OpenPreferences(path)
+	if err != nil {
+		t.Fatalf("OpenPreferences: %v", err)
+	}
+	for _, kind := range Kinds {
+		want := kind != DueSoon && kind != Overdue
+		if got := reopened.Allows(1, kind); got != want {
+			t.Errorf("member 1 %s = %v after reopen, want %v", kind, got, want)
+		}
+		if !reopened.Allows(2, kind) {
+			t.Errorf("member 2 %s disabled after reopen", kind)
+		}
+	}
+}
+
+func TestPreferencesInMemory(t *testing.T) {
+	prefs, err := OpenPreferences("")
+	if err != nil {
+		t.Fatalf("OpenPreferences: %v", err)
+	}
+	if err := prefs.Set(1, Overdue, false); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if prefs.Allows(1, Overdue) {
+		t.Error("Overdue still allowed after opting out")
+	}
+	if err := prefs.Set(1, Overdue, true); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !prefs.Allows(1, Overdue) {
+		t.Error("Overdue not allowed after opting back in")
+	}
+}
+
+func TestOpenPreferencesRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+	os.WriteFile(path, []byte("not json"), 0o644)
+	if _, err := OpenPreferences(path); err == nil {
+		t.Error("OpenPreferences of a corrupt file succeeded")
+	}
+}
@@ -0,0 +1,97 @@
+package recommend
+
+import "sort"
+
+// Score is a recommended book and how strongly it is recommended: the
+// number of readers it shares with the books it was recommended for.
+type Score struct {
+	BookID int
+	Score  int
+}
+
+// History records which members have borrowed which books and derives
+// "readers also borrowed" recommendations from it. It is not safe for
+// concurrent use; the library guards it with its own lock.
+type History struct {
+	readers map[int]map[int]bool // book ID -> members who borrowed it
+	read    map[int]map[int]bool // member ID -> books they borrowed
+}
+
+// NewHistory returns an empty history.
+func NewHistory() *History {
+	return &History{
+		readers: make(map[int]map[int]bool),
+		read:    make(map[int]map[int]bool),
+	}
+}
+
+// Record notes that a member borrowed a book. Borrowing the same book again
+// does not count twice.
+func (h *History) Record(memberID, bookID int) {
+	if h.readers[bookID] == nil {
+		h.readers[bookID] = make(map[int]bool)
+	}
+	h.readers[bookID][memberID] = true
+	if h.read[memberID] == nil {
+		h.read[memberID] = make(map[int]bool)
+	}
+	h.read[memberID][bookID] = true
+}
+
+// HasRead reports whether the member has ever borrowed the book.
+func (h *History) HasRead(memberID, bookID int) bool {
+	return h.read[memberID][bookID]
+}
+
+// AlsoBorrowed ranks the other books borrowed by readers of bookID, by the
+// number of readers they share with it.
+func (h *History) AlsoBorrowed(bookID int) []Score {
+	counts := make(map[int]int)
+	for memberID := range h.readers[bookID] {
+		for other := range h.read[memberID] {
+			if other != bookID {
+				counts[other]++
+			}
+		}
+	}
+	return ranked(counts)
+}
+
+// ForMember suggests books the member has not borrowed, adding up for each
+// candidate the readers it shares with every book the member has read. A
+// member with no history, or whose books have no co-readers, gets the most
+// widely read books instead.
+func (h *History) ForMember(memberID int) []Score {
+	counts := make(map[int]int)
+	for bookID := range h.read[memberID] {
+		for _, s := range h.AlsoBorrowed(bookID) {
+			if !h.read[memberID][s.BookID] {
+				counts[s.BookID] += s.Score
+			}
+		}
+	}
+	if len(counts) == 0 {
+		for bookID, readers := range h.readers {
+			if !h.read[memberID][bookID] {
+				counts[bookID] = len(readers)
+			}
+		}
+	}
+	return ranked(counts)
+}
+
+// ranked orders scores from highest to lowest, breaking ties by book ID so
+// results are stable.
+func ranked(counts map[int]int) []Score {
+	scores := make([]Score, 0, len(counts))
+	for bookID, n := range counts {
+		scores = append(scores, Score{BookID: bookID, Score: n})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].BookID < scores[j].BookID
+	})
+	return scores
+}
@@ -0,0 +1,60 @@
+package recommend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newHistory(loans map[int][]int) *History {
+	h := NewHistory()
+	for memberID, books := range loans {
+		for _, bookID := range books {
+			h.Record(memberID, bookID)
+		}
+	}
+	return h
+}
+
+func TestAlsoBorrowed(t *testing.T) {
+	h := newHistory(map[int][]int{
+		1: {101, 102, 103},
+		2: {101, 102},
+		3: {101, 104, 101}, // Borrowing 101 twice counts once.
+		4: {105},
+	})
+	got := h.AlsoBorrowed(101)
+	want := []Score{{102, 2}, {103, 1}, {104, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("AlsoBorrowed(101) = %v, want %v", got, want)
+	}
+	if got := h.AlsoBorrowed(999); len(got) != 0 {
+		t.Fatalf("AlsoBorrowed of an unread book = %v, want none", got)
+	}
+}
+
+func TestForMember(t *testing.T) {
+	h := newHistory(map[int][]int{
+		1: {101, 102},
+		2: {101, 102, 103},
+		3: {102, 104},
+		4: {103},
+	})
+
+	// Member 1 read 101 and 102: 103 shares readers with both (1 + 1), 104
+	// only with 102.
+	got := h.ForMember(1)
+	want := []Score{{103, 2}, {104, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ForMember(1) = %v, want %v", got, want)
+	}
+	if !h.HasRead(1, 101) || h.HasRead(1, 103) {
+		t.Fatal("HasRead does not reflect member 1's loans")
+	}
+
+	// A member with no history gets the most widely read books.
+	got = h.ForMember(9)
+	want = []Score{{102, 3}, {101, 2}, {103, 2}, {104, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ForMember(9) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,238 @@
+package reports
+
+import (
+	"library_management/events"
+	"library_management/models"
+	"sort"
+	"time"
+)
+
+// Options controls how a circulation report is computed.
+type Options struct {
+	From  time.Time               // Only count activity at or after From (zero for no lower bound)
+	To    time.Time               // Only count activity before To (zero for no upper bound)
+	Top   int                     // Length of the ranked lists (default 10)
+	Now   time.Time               // Reference time for overdue loans (default time.Now)
+	Loans []models.Loan           // Loans open now; those past their due date are overdue
+	Title func(bookID int) string // Resolves book titles; may be nil
+}
+
+// TitleCount is a book and how many times it was borrowed.
+type TitleCount struct {
+	BookID int
+	Title  string
+	Loans  int
+}
+
+// MemberCount is a member and how many books they borrowed.
+type MemberCount struct {
+	MemberID int
+	Loans    int
+}
+
+// ReservationStats summarizes what happened to reservations.
+type ReservationStats struct {
+	Total     int // Reservations made in the period
+	Fulfilled int // Followed by the same member borrowing the book
+	Expired   int // Auto-cancelled before being borrowed
+	Cancelled int // Ended because the member was removed or the book was lost, damaged or withdrawn
+	Open      int // Neither fulfilled, expired nor cancelled (yet)
+}
+
+// FulfillmentRate is the fraction of reservations that were fulfilled.
+func (s ReservationStats) FulfillmentRate() float64 {
+	return ratio(s.Fulfilled, s.Total)
+}
+
+// ExpiryRate is the fraction of reservations that expired.
+func (s ReservationStats) ExpiryRate() float64 {
+	return ratio(s.Expired, s.Total)
+}
+
+// OverdueLoan is an open loan past its due date.
+type OverdueLoan struct {
+	BookID     int
+	Title      string
+	MemberID   int
+	BorrowedAt time.Time
+	DueDate    time.Time
+}
+
+// Circulation is a management report computed from library events.
+type Circulation struct {
+	From, To            time.Time
+	PopularTitles       []TitleCount
+	ActiveMembers       []MemberCount
+	Reservations        ReservationStats
+	CompletedLoans      int
+	AverageLoanDuration time.Duration
+	Overdue             []OverdueLoan
+}
+
+// openLoan tracks a borrow until the book comes back.
+type openLoan struct {
+	memberID   int
+	borrowedAt time.Time
+}
+
+// pendingReservation tracks a reservation until it is borrowed, expires or
+// is cancelled.
+type pendingReservation struct {
+	memberID int
+	counted  bool // Made within the reporting period
+}
+
+// BuildCirculation computes a circulation report from events in the order
+// they happened, as stored by the audit log. Loans and reservations are
+// attributed to the period in which they started. Overdue loans are taken
+// from opts.Loans rather than the events, since the library knows which loans
+// are open and when they are due even if the history is incomplete; they are
+// listed regardless of the period.
+func BuildCirculation(evts []events.Event, opts Options) Circulation {
+	if opts.Top <= 0 {
+		opts.Top = 10
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	inPeriod := func(t time.Time) bool {
+		return (opts.From.IsZero() || !t.Before(opts.From)) && (opts.To.IsZero() || t.Before(opts.To))
+	}
+
+	titleLoans := make(map[int]int)
+	memberLoans := make(map[int]int)
+	open := make(map[int]openLoan)               // Keyed by book ID
+	reserved := make(map[int]pendingReservation) // Keyed by book ID
+	var stats ReservationStats
+	var totalDuration time.Duration
+	completed := 0
+
+	for _, e := range evts {
+		switch e.Type {
+		case events.BookReserved:
+			counted := inPeriod(e.Time)
+			if counted {
+				stats.Total++
+			}
+			reserved[e.BookID] = pendingReservation{memberID: e.MemberID, counted: counted}
+
+		case events.ReservationExpired:
+			if r, ok := reserved[e.BookID]; ok && r.memberID == e.MemberID {
+				if r.counted {
+					stats.Expired++
+				}
+				delete(reserved, e.BookID)
+			}
+
+		case events.ReservationCancelled:
+			if r, ok := reserved[e.BookID]; ok && r.memberID == e.MemberID {
+				if r.counted {
+					stats.Cancelled++
+				}
+				delete(reserved, e.BookID)
+			}
+
+		case events.BookBorrowed:
+			if r, ok := reserved[e.BookID]; ok {
+				if r.counted && r.memberID == e.MemberID {
+					stats.Fulfilled++
+				}
+				delete(reserved, e.BookID)
+			}
+			if inPeriod(e.Time) {
+				titleLoans[e.BookID]++
+				memberLoans[e.MemberID]++
+			}
+			open[e.BookID] = openLoan{memberID: e.MemberID, borrowedAt: e.Time}
+
+		case events.BookReturned:
+			if loan, ok := open[e.BookID]; ok && loan.memberID == e.MemberID {
+				if inPeriod(loan.borrowedAt) {
+					totalDuration += e.Time.Sub(loan.borrowedAt)
+					completed++
+				}
+				delete(open, e.BookID)
+			}
+
+		case events.BookStatusChanged:
+			// Lost, damaged or withdrawn books end any loan or hold.
+			delete(open, e.BookID)
+			if r, ok := reserved[e.BookID]; ok {
+				if r.counted {
+					stats.Cancelled++
+				}
+				delete(reserved, e.BookID)
+			}
+		}
+	}
+	stats.Open = stats.Total - stats.Fulfilled - stats.Expired - stats.Cancelled
+
+	report := Circulation{
+		From:           opts.From,
+		To:             opts.To,
+		Reservations:   stats,
+		CompletedLoans: completed,
+	}
+	if completed > 0 {
+		report.AverageLoanDuration = totalDuration / time.Duration(completed)
+	}
+
+	for bookID, n := range titleLoans {
+		report.PopularTitles = append(report.PopularTitles, TitleCount{BookID: bookID, Title: title(opts, bookID), Loans: n})
+	}
+	sort.Slice(report.PopularTitles, func(i, j int) bool {
+		a, b := report.PopularTitles[i], report.PopularTitles[j]
+		if a.Loans != b.Loans {
+			return a.Loans > b.Loans
+		}
+		return a.BookID < b.BookID
+	})
+	report.PopularTitles = truncate(report.PopularTitles, opts.Top)
+
+	for memberID, n := range memberLoans {
+		report.ActiveMembers = append(report.ActiveMembers, MemberCount{MemberID: memberID, Loans: n})
+	}
+	sort.Slice(report.ActiveMembers, func(i, j int) bool {
+		a, b := report.ActiveMembers[i], report.ActiveMembers[j]
+		if a.Loans != b.Loans {
+			return a.Loans > b.Loans
+		}
+		return a.MemberID < b.MemberID
+	})
+	report.ActiveMembers = truncate(report.ActiveMembers, opts.Top)
+
+	for _, loan := range opts.Loans {
+		if opts.Now.After(loan.DueDate) {
+			report.Overdue = append(report.Overdue, OverdueLoan{
+				BookID:     loan.BookID,
+				Title:      title(opts, loan.BookID),
+				MemberID:   loan.MemberID,
+				BorrowedAt: loan.BorrowedAt,
+				DueDate:    loan.DueDate,
+			})
+		}
+	}
+	sort.SliceStable(report.Overdue, func(i, j int) bool { return report.Overdue[i].DueDate.Before(report.Overdue[j].DueDate) })
+	return report
+}
+
+func title(opts Options, bookID int) string {
+	if opts.Title == nil {
+		return ""
+	}
+	return opts.Title(bookID)
+}
+
+func truncate[T any](s []T, n int) []T {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
@@ -0,0 +1,112 @@
+package reports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteText writes the report in a human-readable layout.
+func WriteText(w io.Writer, r Circulation) error {
+	p := &printer{w: w}
+	p.printf("Circulation Report (%s)\n", period(r))
+
+	p.printf("\nMost Borrowed Titles:\n")
+	if len(r.PopularTitles) == 0 {
+		p.printf("  none\n")
+	}
+	for i, t := range r.PopularTitles {
+		p.printf("  %d. %s (ID %d): %d loan(s)\n", i+1, orUnknown(t.Title), t.BookID, t.Loans)
+	}
+
+	p.printf("\nMost Active Members:\n")
+	if len(r.ActiveMembers) == 0 {
+		p.printf("  none\n")
+	}
+	for i, m := range r.ActiveMembers {
+		p.printf("  %d. Member %d: %d loan(s)\n", i+1, m.MemberID, m.Loans)
+	}
+
+	s := r.Reservations
+	p.printf("\nReservations: %d made, %d fulfilled (%.0f%%), %d expired (%.0f%%), %d cancelled, %d open\n",
+		s.Total, s.Fulfilled, s.FulfillmentRate()*100, s.Expired, s.ExpiryRate()*100, s.Cancelled, s.Open)
+
+	p.printf("Average Loan Duration: %s over %d returned loan(s)\n", formatDuration(r.AverageLoanDuration), r.CompletedLoans)
+
+	p.printf("\nOverdue Loans:\n")
+	if len(r.Overdue) == 0 {
+		p.printf("  none\n")
+	}
+	for _, o := range r.Overdue {
+		p.printf("  %s (ID %d), member %d, due %s\n", orUnknown(o.Title), o.BookID, o.MemberID, o.DueDate.Format("2006-01-02"))
+	}
+	return p.err
+}
+
+// WriteCSV writes the report as CSV rows of section, id, label and value,
+// so every part of the report fits a single table.
+func WriteCSV(w io.Writer, r Circulation) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"section", "id", "label", "value"})
+	for _, t := range r.PopularTitles {
+		cw.Write([]string{"popular_title", strconv.Itoa(t.BookID), t.Title, strconv.Itoa(t.Loans)})
+	}
+	for _, m := range r.ActiveMembers {
+		cw.Write([]string{"active_member", strconv.Itoa(m.MemberID), "", strconv.Itoa(m.Loans)})
+	}
+	s := r.Reservations
+	cw.Write([]string{"reservations", "", "total", strconv.Itoa(s.Total)})
+	cw.Write([]string{"reservations", "", "fulfilled", strconv.Itoa(s.Fulfilled)})
+	cw.Write([]string{"reservations", "", "expired", strconv.Itoa(s.Expired)})
+	cw.Write([]string{"reservations", "", "cancelled", strconv.Itoa(s.Cancelled)})
+	cw.Write([]string{"reservations", "", "open", strconv.Itoa(s.Open)})
+	cw.Write([]string{"reservations", "", "fulfillment_rate", strconv.FormatFloat(s.FulfillmentRate(), 'f', 4, 64)})
+	cw.Write([]string{"reservations", "", "expiry_rate", strconv.FormatFloat(s.ExpiryRate(), 'f', 4, 64)})
+	cw.Write([]string{"loans", "", "completed", strconv.Itoa(r.CompletedLoans)})
+	cw.Write([]string{"loans", "", "average_duration_seconds", strconv.FormatFloat(r.AverageLoanDuration.Seconds(), 'f', 0, 64)})
+	for _, o := range r.Overdue {
+		cw.Write([]string{"overdue", strconv.Itoa(o.BookID), o.Title, fmt.Sprintf("member %d due %s", o.MemberID, o.DueDate.Format(time.RFC3339))})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// printer remembers the first write error so WriteText can check it once.
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func period(r Circulation) string {
+	from, to := "beginning", "now"
+	if !r.From.IsZero() {
+		from = r.From.Format("2006-01-02")
+	}
+	if !r.To.IsZero() {
+		to = r.To.Format("2006-01-02")
+	}
+	return from + " to " + to
+}
+
+func orUnknown(title string) string {
+	if title == "" {
+		return "(unknown title)"
+	}
+	return title
+}
+
+// formatDuration shows days for long durations and rounds short ones.
+func formatDuration(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%.1f days", d.Hours()/24)
+	}
+	return d.Round(time.Second).String()
+}
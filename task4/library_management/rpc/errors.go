@@ -0,0 +1,124 @@
+package rpc
+
+import (
+	"errors"
+	"library_management/concurrency"
+	"library_management/models"
+	"library_management/services"
+)
+
+// JSON-RPC 2.0 error codes. The first five are defined by the specification;
+// the rest are library errors in the range reserved for servers.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	CodeNotFound        = -32001 // Book, member or branch does not exist; data.resource says which
+	CodeReserved        = -32002 // Book is reserved by another member
+	CodeAlreadyBorrowed = -32003 // Book is already borrowed
+	CodeConflict        = -32004 // Any other operation the book or member's state does not allow
+	CodeSuspended       = -32005 // Member is suspended
+	CodeBatchRejected   = -32006 // Batch not applied; data.items gives each failing book's reason
+	CodeTimeout         = -32007 // Command workers did not answer in time
+	CodeUnavailable     = -32008 // Library is shutting down
+)
+
+// Error is a JSON-RPC error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NotFoundData is the data of a CodeNotFound error.
+type NotFoundData struct {
+	Resource string `json:"resource"` // "book", "member" or "branch"
+}
+
+// BatchData is the data of a CodeBatchRejected error.
+type BatchData struct {
+	Items []ItemError `json:"items"`
+}
+
+// ItemError is why one book of a batch was rejected.
+type ItemError struct {
+	BookID int    `json:"book_id"`
+	Code   int    `json:"code"`
+	Error  string `json:"error"`
+}
+
+// invalidParams reports malformed or missing method parameters.
+func invalidParams(message string) *Error {
+	return &Error{Code: CodeInvalidParams, Message: "invalid params: " + message}
+}
+
+// errorFor maps a library or command pool error to a JSON-RPC error.
+func errorFor(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	var batchErr *services.BatchError
+	if errors.As(err, &batchErr) {
+		data := BatchData{Items: make([]ItemError, 0, len(batchErr.Items))}
+		for _, item := range batchErr.Items {
+			data.Items = append(data.Items, ItemError{BookID: item.BookID, Code: codeFor(item.Err), Error: item.Err.Error()})
+		}
+		return &Error{Code: CodeBatchRejected, Message: "batch rejected; no books were processed", Data: data}
+	}
+
+	e := &Error{Code: codeFor(err), Message: err.Error()}
+	switch {
+	case errors.Is(err, services.ErrBookNotFound):
+		e.Data = NotFoundData{Resource: "book"}
+	case errors.Is(err, services.ErrMemberNotFound):
+		e.Data = NotFoundData{Resource: "member"}
+	case errors.Is(err, services.ErrBranchNotFound):
+		e.Data = NotFoundData{Resource: "branch"}
+	}
+	return e
+}
+
+func codeFor(err error) int {
+	switch {
+	case errors.Is(err, services.ErrBookNotFound),
+		errors.Is(err, services.ErrMemberNotFound),
+		errors.Is(err, services.ErrBranchNotFound):
+		return CodeNotFound
+	case errors.Is(err, services.ErrBookReserved):
+		return CodeReserved
+	case errors.Is(err, services.ErrBookBorrowed):
+		return CodeAlreadyBorrowed
+	case errors.Is(err, models.ErrUnknownStatus),
+		errors.Is(err, services.ErrEmptyBatch),
+		errors.Is(err, services.ErrDuplicateBook):
+		return CodeInvalidParams
+	case errors.Is(err, services.ErrBookNotAvailable),
+		errors.Is(err, services.ErrBookExists),
+		errors.Is(err, services.ErrMemberExists),
+		errors.Is(err, services.ErrBranchExists),
+		errors.Is(err, services.ErrNotBorrowedByMember),
+		errors.Is(err, models.ErrInvalidTransition),
+		errors.Is(err, services.ErrBookOnLoan),
+		errors.Is(err, services.ErrMemberHasLoans),
+		errors.Is(err, services.ErrWrongBranch),
+		errors.Is(err, services.ErrBookInTransit),
+		errors.Is(err, services.ErrNotInTransit):
+		return CodeConflict
+	case errors.Is(err, services.ErrMemberSuspended):
+		return CodeSuspended
+	case errors.Is(err, concurrency.ErrRequestTimeout):
+		return CodeTimeout
+	case errors.Is(err, concurrency.ErrPoolClosed), errors.Is(err, concurrency.ErrRequestCanceled):
+		return CodeUnavailable
+	default:
+		return CodeInternalError
+	}
+}
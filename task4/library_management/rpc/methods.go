@@ -0,0 +1,474 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"library_management/concurrency"
+	"library_management/models"
+	"library_management/services"
+	"time"
+)
+
+// method handles one JSON-RPC method. params is the raw "params" member,
+// nil if the request had none.
+type method func(s *Server, ctx context.Context, params json.RawMessage) (any, error)
+
+// methods maps JSON-RPC method names to handlers. Names follow the
+// LibraryManager operations they call; parameters are always by name.
+var methods map[string]method
+
+func init() {
+	methods = map[string]method{
+		"ListBooks":          (*Server).listBooks,
+		"ListAvailableBooks": (*Server).listAvailableBooks,
+		"SearchBooks":        (*Server).searchBooks,
+		"GetBook":            (*Server).getBook,
+		"AddBook":            (*Server).addBook,
+		"RemoveBook":         (*Server).removeBook,
+		"UpdateBookStatus":   (*Server).updateBookStatus,
+		"ListMembers":        (*Server).listMembers,
+		"GetMember":          (*Server).getMember,
+		"AddMember":          (*Server).addMember,
+		"ListLoans":          (*Server).listLoans,
+		"BorrowBook":         (*Server).borrowBook,
+		"ReturnBook":         (*Server).returnBook,
+		"ReserveBook":        (*Server).reserveBook,
+		"BorrowBooks":        (*Server).borrowBooks,
+		"ReturnBooks":        (*Server).returnBooks,
+		"ListBranches":       (*Server).listBranches,
+		"TransferBook":       (*Server).transferBook,
+		"ReceiveBook":        (*Server).receiveBook,
+		"AlsoBorrowed":       (*Server).alsoBorrowed,
+		"RecommendFor":       (*Server).recommendFor,
+	}
+}
+
+// Book is a book in results.
+type Book struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Status      string `json:"status"`
+	ReservedBy  int    `json:"reserved_by,omitempty"`
+	HomeBranch  int    `json:"home_branch"`
+	Branch      int    `json:"branch"`
+	Destination int    `json:"destination,omitempty"`
+}
+
+// Member is a member in results.
+type Member struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	BorrowedBooks []int  `json:"borrowed_books"` // IDs of the books on loan
+	Suspended     bool   `json:"suspended"`
+}
+
+// Loan is a loan in results.
+type Loan struct {
+	BookID     int       `json:"book_id"`
+	Title      string    `json:"title"`
+	BorrowedAt time.Time `json:"borrowed_at"`
+	DueDate    time.Time `json:"due_date"`
+}
+
+// Branch is a branch in results.
+type Branch struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Recommendation is a recommended book with its score.
+type Recommendation struct {
+	Book
+	Score int `json:"score"`
+}
+
+// Parameter objects. Fields marked Optional may be left out; the others are
+// required.
+type (
+	bookParams struct {
+		BookID int `json:"book_id"`
+	}
+	memberParams struct {
+		MemberID int `json:"member_id"`
+	}
+	searchParams struct {
+		Query string `json:"query"`
+	}
+	addBookParams struct {
+		ID         int    `json:"id"`
+		Title      string `json:"title"`
+		Author     string `json:"author"`
+		HomeBranch int    `json:"home_branch"` // Optional; defaults to the main branch
+	}
+	statusParams struct {
+		BookID int    `json:"book_id"`
+		Status string `json:"status"`
+	}
+	addMemberParams struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	loanParams struct {
+		BookID   int `json:"book_id"`
+		MemberID int `json:"member_id"`
+		BranchID int `json:"branch_id"` // Optional; 0 uses the book's current branch
+	}
+	batchParams struct {
+		BookIDs  []int `json:"book_ids"`
+		MemberID int   `json:"member_id"`
+		BranchID int   `json:"branch_id"` // Optional
+	}
+	moveParams struct {
+		BookID   int `json:"book_id"`
+		BranchID int `json:"branch_id"`
+	}
+	recommendParams struct {
+		BookID   int `json:"book_id"`
+		MemberID int `json:"member_id"`
+		Limit    int `json:"limit"` // Optional; 0 uses services.DefaultRecommendations
+	}
+)
+
+// decode unmarshals by-name params into p, rejecting unknown fields so that
+// typos are reported instead of silently ignored.
+func decode(params json.RawMessage, p any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if params[0] != '{' {
+		return invalidParams("params must be an object")
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(p); err != nil {
+		return invalidParams(err.Error())
+	}
+	return nil
+}
+
+// positive checks that a required ID parameter was given.
+func positive(name string, v int) error {
+	if v <= 0 {
+		return invalidParams(name + " must be a positive integer")
+	}
+	return nil
+}
+
+func (s *Server) listBooks(_ context.Context, _ json.RawMessage) (any, error) {
+	return toBooks(s.library.ListBooks()), nil
+}
+
+func (s *Server) listAvailableBooks(_ context.Context, _ json.RawMessage) (any, error) {
+	return toBooks(s.library.ListAvailableBooks()), nil
+}
+
+func (s *Server) searchBooks(_ context.Context, params json.RawMessage) (any, error) {
+	var p searchParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Query == "" {
+		return nil, invalidParams("query is required")
+	}
+	return toBooks(s.library.SearchBooks(p.Query)), nil
+}
+
+func (s *Server) getBook(_ context.Context, params json.RawMessage) (any, error) {
+	var p bookParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("book_id", p.BookID); err != nil {
+		return nil, err
+	}
+	return s.book(p.BookID)
+}
+
+func (s *Server) addBook(ctx context.Context, params json.RawMessage) (any, error) {
+	var p addBookParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("id", p.ID); err != nil {
+		return nil, err
+	}
+	if p.Title == "" {
+		return nil, invalidParams("title is required")
+	}
+	if p.HomeBranch != 0 && !s.branchExists(p.HomeBranch) {
+		return nil, services.ErrBranchNotFound
+	}
+	book := models.Book{ID: p.ID, Title: p.Title, Author: p.Author, HomeBranch: p.HomeBranch}
+	if err := s.do(ctx, concurrency.Command{Kind: concurrency.CommandAddBook, Book: book}); err != nil {
+		return nil, err
+	}
+	return s.book(p.ID)
+}
+
+func (s *Server) removeBook(ctx context.Context, params json.RawMessage) (any, error) {
+	var p bookParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("book_id", p.BookID); err != nil {
+		return nil, err
+	}
+	return nil, s.do(ctx, concurrency.Command{Kind: concurrency.CommandRemoveBook, BookID: p.BookID})
+}
+
+func (s *Server) updateBookStatus(ctx context.Context, params json.RawMessage) (any, error) {
+	var p statusParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("book_id", p.BookID); err != nil {
+		return nil, err
+	}
+	status, err := models.ParseBookStatus(p.Status)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.do(ctx, concurrency.Command{Kind: concurrency.CommandUpdateStatus, BookID: p.BookID, Status: status}); err != nil {
+		return nil, err
+	}
+	return s.book(p.BookID)
+}
+
+func (s *Server) listMembers(_ context.Context, _ json.RawMessage) (any, error) {
+	members := s.library.ListMembers()
+	res := make([]Member, 0, len(members))
+	for _, m := range members {
+		res = append(res, toMember(m))
+	}
+	return res, nil
+}
+
+func (s *Server) getMember(_ context.Context, params json.RawMessage) (any, error) {
+	var p memberParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("member_id", p.MemberID); err != nil {
+		return nil, err
+	}
+	member, err := s.library.GetMember(p.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	return toMember(member), nil
+}
+
+func (s *Server) addMember(ctx context.Context, params json.RawMessage) (any, error) {
+	var p addMemberParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("id", p.ID); err != nil {
+		return nil, err
+	}
+	if p.Name == "" {
+		return nil, invalidParams("name is required")
+	}
+	member := models.Member{ID: p.ID, Name: p.Name, Loans: []models.Loan{}}
+	if err := s.do(ctx, concurrency.Command{Kind: concurrency.CommandAddMember, Member: member}); err != nil {
+		return nil, err
+	}
+	return toMember(member), nil
+}
+
+func (s *Server) listLoans(_ context.Context, params json.RawMessage) (any, error) {
+	var p memberParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("member_id", p.MemberID); err != nil {
+		return nil, err
+	}
+	if _, err := s.library.GetMember(p.MemberID); err != nil {
+		return nil, err
+	}
+	loans := s.library.ListLoans(p.MemberID)
+	res := make([]Loan, 0, len(loans))
+	for _, loan := range loans {
+		book, err := s.library.GetBook(loan.BookID)
+		if err != nil {
+			continue
+		}
+		res = append(res, Loan{BookID: loan.BookID, Title: book.Title, BorrowedAt: loan.BorrowedAt, DueDate: loan.DueDate})
+	}
+	return res, nil
+}
+
+func (s *Server) borrowBook(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.loan(ctx, params, concurrency.CommandBorrow)
+}
+
+func (s *Server) returnBook(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.loan(ctx, params, concurrency.CommandReturn)
+}
+
+func (s *Server) reserveBook(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.loan(ctx, params, concurrency.CommandReserve)
+}
+
+// loan runs a borrow, return or reserve and answers with the book.
+func (s *Server) loan(ctx context.Context, params json.RawMessage, kind concurrency.CommandKind) (any, error) {
+	var p loanParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("book_id", p.BookID); err != nil {
+		return nil, err
+	}
+	if err := positive("member_id", p.MemberID); err != nil {
+		return nil, err
+	}
+	if err := s.do(ctx, concurrency.Command{Kind: kind, BookID: p.BookID, MemberID: p.MemberID, Branch: p.BranchID}); err != nil {
+		return nil, err
+	}
+	return s.book(p.BookID)
+}
+
+func (s *Server) borrowBooks(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.batch(ctx, params, concurrency.CommandBorrowBatch)
+}
+
+func (s *Server) returnBooks(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.batch(ctx, params, concurrency.CommandReturnBatch)
+}
+
+// batch runs an all-or-nothing batch and answers with the books involved.
+func (s *Server) batch(ctx context.Context, params json.RawMessage, kind concurrency.CommandKind) (any, error) {
+	var p batchParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("member_id", p.MemberID); err != nil {
+		return nil, err
+	}
+	if err := s.do(ctx, concurrency.Command{Kind: kind, BookIDs: p.BookIDs, MemberID: p.MemberID, Branch: p.BranchID}); err != nil {
+		return nil, err
+	}
+	books := make([]models.Book, 0, len(p.BookIDs))
+	for _, id := range p.BookIDs {
+		if book, err := s.library.GetBook(id); err == nil {
+			books = append(books, book)
+		}
+	}
+	return toBooks(books), nil
+}
+
+func (s *Server) listBranches(_ context.Context, _ json.RawMessage) (any, error) {
+	branches := s.library.ListBranches()
+	res := make([]Branch, 0, len(branches))
+	for _, b := range branches {
+		res = append(res, Branch{ID: b.ID, Name: b.Name})
+	}
+	return res, nil
+}
+
+func (s *Server) transferBook(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.move(ctx, params, concurrency.CommandTransfer)
+}
+
+func (s *Server) receiveBook(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.move(ctx, params, concurrency.CommandReceive)
+}
+
+func (s *Server) move(ctx context.Context, params json.RawMessage, kind concurrency.CommandKind) (any, error) {
+	var p moveParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("book_id", p.BookID); err != nil {
+		return nil, err
+	}
+	if err := positive("branch_id", p.BranchID); err != nil {
+		return nil, err
+	}
+	if err := s.do(ctx, concurrency.Command{Kind: kind, BookID: p.BookID, Branch: p.BranchID}); err != nil {
+		return nil, err
+	}
+	return s.book(p.BookID)
+}
+
+func (s *Server) alsoBorrowed(_ context.Context, params json.RawMessage) (any, error) {
+	var p recommendParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("book_id", p.BookID); err != nil {
+		return nil, err
+	}
+	recs, err := s.library.AlsoBorrowed(p.BookID, p.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return toRecommendations(recs), nil
+}
+
+func (s *Server) recommendFor(_ context.Context, params json.RawMessage) (any, error) {
+	var p recommendParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if err := positive("member_id", p.MemberID); err != nil {
+		return nil, err
+	}
+	recs, err := s.library.RecommendFor(p.MemberID, p.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return toRecommendations(recs), nil
+}
+
+// book looks up a book for a result.
+func (s *Server) book(id int) (any, error) {
+	book, err := s.library.GetBook(id)
+	if err != nil {
+		return nil, err
+	}
+	return toBook(book), nil
+}
+
+func (s *Server) branchExists(id int) bool {
+	for _, branch := range s.library.ListBranches() {
+		if branch.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func toBook(b models.Book) Book {
+	return Book{
+		ID: b.ID, Title: b.Title, Author: b.Author, Status: string(b.Status), ReservedBy: b.ReservedBy,
+		HomeBranch: b.HomeBranch, Branch: b.Branch, Destination: b.Destination,
+	}
+}
+
+func toBooks(books []models.Book) []Book {
+	res := make([]Book, 0, len(books))
+	for _, b := range books {
+		res = append(res, toBook(b))
+	}
+	return res
+}
+
+func toMember(m models.Member) Member {
+	ids := make([]int, 0, len(m.Loans))
+	for _, loan := range m.Loans {
+		ids = append(ids, loan.BookID)
+	}
+	return Member{ID: m.ID, Name: m.Name, BorrowedBooks: ids, Suspended: m.Suspended}
+}
+
+func toRecommendations(recs []services.Recommendation) []Recommendation {
+	res := make([]Recommendation, 0, len(recs))
+	for _, rec := range recs {
+		res = append(res, Recommendation{Book: toBook(rec.Book), Score: rec.Score})
+	}
+	return res
+}
@@ -0,0 +1,153 @@
+// Package rpc serves the library as line-delimited JSON-RPC 2.0, so another
+// program can drive it as a subprocess over stdin and stdout.
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"library_management/concurrency"
+	"library_management/services"
+	"time"
+)
+
+// commandTimeout bounds how long a call waits for the command workers.
+const commandTimeout = 3 * time.Second
+
+// maxLine is the longest request line accepted.
+const maxLine = 1 << 20
+
+// Server answers JSON-RPC calls against a library. Reads call the library
+// directly; mutations go through the command pool like the console and the
+// REST API.
+type Server struct {
+	library services.LibraryManager
+	pool    *concurrency.CommandPool
+}
+
+// NewServer creates a Server.
+func NewServer(library services.LibraryManager, pool *concurrency.CommandPool) *Server {
+	return &Server{library: library, pool: pool}
+}
+
+// request is a JSON-RPC request or notification. A notification has no id
+// and gets no response.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// response is a JSON-RPC response. Exactly one of Result and Error is set.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Serve reads one request, or one batch array of requests, per line from r
+// and writes each response as a line to w, until r is exhausted or ctx is
+// done. Requests are handled in order.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLine)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if reply := s.handleLine(ctx, line); reply != nil {
+			if err := enc.Encode(reply); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handleLine answers one line. It returns nil when nothing should be
+// written, i.e. for notifications.
+func (s *Server) handleLine(ctx context.Context, line []byte) any {
+	if line[0] != '[' {
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			return errorResponse(nil, &Error{Code: CodeParseError, Message: "parse error"})
+		}
+		if reply := s.handle(ctx, req); reply != nil {
+			return reply
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(line, &batch); err != nil {
+		return errorResponse(nil, &Error{Code: CodeParseError, Message: "parse error"})
+	}
+	if len(batch) == 0 {
+		return errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "empty batch"})
+	}
+	var replies []*response
+	for _, raw := range batch {
+		var req request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			replies = append(replies, errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "invalid request"}))
+			continue
+		}
+		if reply := s.handle(ctx, req); reply != nil {
+			replies = append(replies, reply)
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	return replies
+}
+
+// handle runs one request.
+func (s *Server) handle(ctx context.Context, req request) *response {
+	notification := req.ID == nil
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, &Error{Code: CodeInvalidRequest, Message: "invalid request"})
+	}
+
+	m, ok := methods[req.Method]
+	if !ok {
+		if notification {
+			return nil
+		}
+		return errorResponse(req.ID, &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method})
+	}
+	result, err := m(s, ctx, req.Params)
+	if notification {
+		return nil
+	}
+	if err != nil {
+		return errorResponse(req.ID, errorFor(err))
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+	return &response{JSONRPC: "2.0", Result: result, ID: req.ID}
+}
+
+func errorResponse(id json.RawMessage, err *Error) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", Error: err, ID: id}
+}
+
+// do runs a mutation through the command pool, bounded by commandTimeout.
+func (s *Server) do(ctx context.Context, cmd concurrency.Command) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	return s.pool.Do(ctx, cmd)
+}
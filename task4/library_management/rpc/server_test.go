@@ -0,0 +1,148 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"library_management/concurrency"
+	"library_management/models"
+	"library_management/services"
+	"strings"
+	"testing"
+)
+
+// serve runs the lines through a server and returns its output lines.
+func serve(t *testing.T, lines ...string) []string {
+	t.Helper()
+	library := services.NewLibrary()
+	t.Cleanup(library.Close)
+	library.AddMember(models.Member{ID: 1, Name: "Alice"})
+	library.AddMember(models.Member{ID: 2, Name: "Bob"})
+	library.AddBook(models.Book{ID: 101, Title: "Go Programming", Author: "John Doe"})
+	pool := concurrency.NewCommandPool(library, 2, 4)
+	t.Cleanup(func() { pool.Shutdown(context.Background()) })
+
+	var out strings.Builder
+	in := strings.NewReader(strings.Join(lines, "\n"))
+	if err := NewServer(library, pool).Serve(context.Background(), in, &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+}
+
+func decodeLines[T any](t *testing.T, lines []string) []T {
+	t.Helper()
+	res := make([]T, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &res[i]); err != nil {
+			t.Fatalf("decode %s: %v", line, err)
+		}
+	}
+	return res
+}
+
+func TestErrorCodes(t *testing.T) {
+	replies := decodeLines[response](t, serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"ReserveBook","params":{"book_id":101,"member_id":1}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"BorrowBook","params":{"book_id":101,"member_id":2}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"BorrowBook","params":{"book_id":101,"member_id":1}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"BorrowBook","params":{"book_id":101,"member_id":1}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"GetMember","params":{"member_id":9}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"GetBook","params":{"book":101}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"Unknown"}`,
+		`{"jsonrpc":"2.0","method":"ListBooks"}`, // Notification: no response
+		`not json`,
+	))
+
+	want := []int{0, CodeReserved, 0, CodeAlreadyBorrowed, CodeNotFound, CodeInvalidParams, CodeMethodNotFound, CodeParseError}
+	if len(replies) != len(want) {
+		t.Fatalf("got %d responses, want %d: %+v", len(replies), len(want), replies)
+	}
+	for i, code := range want {
+		got := 0
+		if replies[i].Error != nil {
+			got = replies[i].Error.Code
+		}
+		if got != code {
+			t.Errorf("response %d: code %d, want %d (%+v)", i, got, code, replies[i].Error)
+		}
+	}
+	if data, _ := json.Marshal(replies[4].Error.Data); string(data) != `{"resource":"member"}` {
+		t.Errorf("not found data = %s", data)
+	}
+	if string(replies[7].ID) != "null" {
+		t.Errorf("parse error id = %s, want null", replies[7].ID)
+	}
+}
+
+func TestBatchRequest(t *testing.T) {
+	lines := serve(t,
+		`[{"jsonrpc":"2.0","id":1,"method":"BorrowBooks","params":{"book_ids":[101,999],"member_id":1}},`+
+			`{"jsonrpc":"2.0","method":"BorrowBook","params":{"book_id":101,"member_id":1}},`+
+			`{"jsonrpc":"2.0","id":2,"method":"GetBook","params":{"book_id":101}}]`,
+	)
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want one batch response", len(lines))
+	}
+	replies := decodeLines[[]response](t, lines)[0]
+	if len(replies) != 2 {
+		t.Fatalf("got %d responses, want 2 (notifications are not answered)", len(replies))
+	}
+
+	rejected := replies[0].Error
+	if rejected == nil || rejected.Code != CodeBatchRejected {
+		t.Fatalf("batch borrow error = %+v, want code %d", rejected, CodeBatchRejected)
+	}
+	if data, _ := json.Marshal(rejected.Data); string(data) != `{"items":[{"book_id":999,"code":-32001,"error":"book not found"}]}` {
+		t.Errorf("batch error data = %s", data)
+	}
+	// The notification in the batch still ran.
+	if book, _ := json.Marshal(replies[1].Result); !strings.Contains(string(book), `"status":"Borrowed"`) {
+		t.Errorf("GetBook = %s, want the book borrowed", book)
+	}
+}
+
+func TestAddBookDuplicateID(t *testing.T) {
+	replies := decodeLines[response](t, serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"AddBook","params":{"id":101,"title":"Again"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"GetBook","params":{"book_id":101}}`,
+	))
+	if replies[0].Error == nil || replies[0].Error.Code != CodeConflict {
+		t.Fatalf("AddBook of an existing ID = %+v, want code %d", replies[0].Error, CodeConflict)
+	}
+	if book, _ := json.Marshal(replies[1].Result); !strings.Contains(string(book), `"title":"Go Programming"`) {
+		t.Errorf("GetBook = %s, want the original book", book)
+	}
+}
+
+func TestAddMemberDuplicateID(t *testing.T) {
+	replies := decodeLines[response](t, serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"AddMember","params":{"id":1,"name":"Again"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"GetMember","params":{"member_id":1}}`,
+	))
+	if replies[0].Error == nil || replies[0].Error.Code != CodeConflict {
+		t.Fatalf("AddMember of an existing ID = %+v, want code %d", replies[0].Error, CodeConflict)
+	}
+	if member, _ := json.Marshal(replies[1].Result); !strings.Contains(string(member), `"name":"Alice"`) {
+		t.Errorf("GetMember = %s, want the original member", member)
+	}
+}
+
+func TestReserveUnavailableBookCodes(t *testing.T) {
+	replies := decodeLines[response](t, serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"ReserveBook","params":{"book_id":101,"member_id":1}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ReserveBook","params":{"book_id":101,"member_id":2}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"BorrowBook","params":{"book_id":101,"member_id":1}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"ReserveBook","params":{"book_id":101,"member_id":2}}`,
+	))
+
+	want := []int{0, CodeReserved, 0, CodeAlreadyBorrowed}
+	for i, code := range want {
+		got := 0
+		if replies[i].Error != nil {
+			got = replies[i].Error.Code
+		}
+		if got != code {
+			t.Errorf("response %d: code %d, want %d (%+v)", i, got, code, replies[i].Error)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Scores awarded for each way a query term can match an indexed term.
+const (
+	exactScore  = 3.0
+	prefixScore = 2.0
+	fuzzyScore  = 1.0
+
+	// fuzzyPrefixScore is awarded when a query term is a misspelled prefix
+	// of an indexed term, e.g. "progam" for "programming".
+	fuzzyPrefixScore = 0.5
+
+	// titleWeight makes title matches rank above author matches.
+	titleWeight  = 2.0
+	authorWeight = 1.0
+)
+
+// Result is a single ranked search hit.
+type Result struct {
+	ID    int
+	Score float64
+}
+
+// document keeps the tokenized fields of an indexed book.
+type document struct {
+	title  []string
+	author []string
+}
+
+// Index is an in-memory inverted index over book titles and authors.
+// It supports prefix, multi-term and typo-tolerant (edit distance) queries.
+type Index struct {
+	postings map[string]map[int]struct{} // term -> IDs of documents containing it
+	docs     map[int]document            // Keyed by document ID
+	mu       sync.RWMutex                // Protects postings and docs
+}
+
+// NewIndex creates an empty Index.
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int]struct{}),
+		docs:     make(map[int]document),
+	}
+}
+
+// Add indexes a document, replacing any previous entry with the same ID.
+func (idx *Index) Add(id int, title, author string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+	doc := document{title: Tokenize(title), author: Tokenize(author)}
+	idx.docs[id] = doc
+	for _, term := range append(append([]string{}, doc.title...), doc.author...) {
+		ids, ok := idx.postings[term]
+		if !ok {
+			ids = make(map[int]struct{})
+			idx.postings[term] = ids
+		}
+		ids[id] = struct{}{}
+	}
+}
+
+// Remove drops a document from the index. Unknown IDs are ignored.
+func (idx *Index) Remove(id int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+}
+
+// remove drops a document; the caller must hold the write lock.
+func (idx *Index) remove(id int) {
+	doc, exists := idx.docs[id]
+	if !exists {
+		return
+	}
+	for _, term := range append(append([]string{}, doc.title...), doc.author...) {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Search returns the IDs of documents matching every term of the query,
+// ordered by descending relevance and then by ascending ID.
+func (idx *Index) Search(query string) []Result {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return []Result{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var scores map[int]float64
+	for _, term := range terms {
+		termScores := idx.scoreTerm(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		// Keep only documents that matched all previous terms.
+		for id := range scores {
+			s, ok := termScores[id]
+			if !ok {
+				delete(scores, id)
+				continue
+			}
+			scores[id] += s
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, Result{ID: id, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+// scoreTerm scores every document against a single query term, keeping the
+// best match per document. The caller must hold the read lock.
+func (idx *Index) scoreTerm(term string) map[int]float64 {
+	maxDist := allowedDistance(term)
+	scores := make(map[int]float64)
+	for indexed := range idx.postings {
+		var match float64
+		switch {
+		case indexed == term:
+			match = exactScore
+		case strings.HasPrefix(indexed, term):
+			match = prefixScore
+		case maxDist > 0:
+			if d := editDistance(term, indexed, maxDist); d <= maxDist {
+				match = fuzzyScore / float64(d)
+			} else if d := prefixDistance(term, indexed, maxDist); d <= maxDist {
+				match = fuzzyPrefixScore / float64(d)
+			} else {
+				continue
+			}
+		default:
+			continue
+		}
+		for id := range idx.postings[indexed] {
+			s := match * idx.fieldWeight(id, indexed)
+			if s > scores[id] {
+				scores[id] = s
+			}
+		}
+	}
+	return scores
+}
+
+// fieldWeight reports how much a term counts for a document depending on
+// whether it appears in the title or only in the author.
+func (idx *Index) fieldWeight(id int, term string) float64 {
+	for _, t := range idx.docs[id].title {
+		if t == term {
+			return titleWeight
+		}
+	}
+	return authorWeight
+}
+
+// allowedDistance is the number of typos tolerated for a query term.
+// Short terms must match exactly or by prefix.
+func allowedDistance(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// prefixDistance returns the smallest edit distance between term and any
+// prefix of indexed whose length is within max runes of the term's length.
+func prefixDistance(term, indexed string, max int) int {
+	n, runes := len([]rune(term)), []rune(indexed)
+	best := max + 1
+	for l := n - max; l <= n+max && l <= len(runes); l++ {
+		if l <= 0 {
+			continue
+		}
+		if d := editDistance(term, string(runes[:l]), max); d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+// Tokenize lowercases text and splits it into letter/digit terms.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// editDistance computes the Levenshtein distance between a and b. It stops
+// early and returns max+1 once the distance is known to exceed max.
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > max || -diff > max {
+		return max + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,135 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestIndex() *Index {
+	idx := NewIndex()
+	idx.Add(1, "The Go Programming Language", "Alan Donovan")
+	idx.Add(2, "Introducing Go", "Caleb Doxsey")
+	idx.Add(3, "Programming Pearls", "Jon Bentley")
+	idx.Add(4, "Go in Action", "William Kennedy")
+	idx.Add(5, "The Kennedy Files", "Someone Else")
+	return idx
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []Result
+	}{
+		{
+			name:  "exact title term, ties by ID",
+			query: "Go",
+			want:  []Result{{1, exactScore * titleWeight}, {2, exactScore * titleWeight}, {4, exactScore * titleWeight}},
+		},
+		{
+			name:  "prefix",
+			query: "prog",
+			want:  []Result{{1, prefixScore * titleWeight}, {3, prefixScore * titleWeight}},
+		},
+		{
+			name:  "exact beats prefix",
+			query: "pearl pearls",
+			want:  []Result{{3, (prefixScore + exactScore) * titleWeight}},
+		},
+		{
+			name:  "typo",
+			query: "donovn",
+			want:  []Result{{1, fuzzyScore * authorWeight}},
+		},
+		{
+			name:  "misspelled prefix",
+			query: "progam",
+			want:  []Result{{1, fuzzyPrefixScore * titleWeight}, {3, fuzzyPrefixScore * titleWeight}},
+		},
+		{
+			name:  "title ranks above author",
+			query: "kennedy",
+			want:  []Result{{5, exactScore * titleWeight}, {4, exactScore * authorWeight}},
+		},
+		{
+			name:  "every term must match",
+			query: "go programming",
+			want:  []Result{{1, 2 * exactScore * titleWeight}},
+		},
+		{
+			name:  "title and author terms combine",
+			query: "pearls bentley",
+			want:  []Result{{3, exactScore*titleWeight + exactScore*authorWeight}},
+		},
+		{
+			name:  "short terms are not fuzzy",
+			query: "ga",
+			want:  []Result{},
+		},
+		{
+			name:  "no match",
+			query: "go haskell",
+			want:  []Result{},
+		},
+		{
+			name:  "empty query",
+			query: " - ",
+			want:  []Result{},
+		},
+	}
+
+	idx := newTestIndex()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := idx.Search(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveCleansPostings(t *testing.T) {
+	idx := newTestIndex()
+	idx.Remove(3)
+	idx.Remove(99) // Unknown IDs are ignored.
+
+	for _, term := range []string{"pearls", "jon", "bentley"} {
+		if _, ok := idx.postings[term]; ok {
+			t.Errorf("term %q still indexed after its only document was removed", term)
+		}
+	}
+	if ids := idx.postings["programming"]; len(ids) != 1 {
+		t.Errorf("programming postings = %v, want only document 1", ids)
+	}
+	if got := idx.Search("programming"); len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("Search after Remove = %v, want document 1", got)
+	}
+
+	// Adding an ID again replaces its terms.
+	idx.Add(1, "Learning Go", "Jon Bodner")
+	if _, ok := idx.postings["programming"]; ok {
+		t.Error("replaced title still indexed")
+	}
+	if got := idx.Search("bodner"); len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("Search for the new author = %v, want document 1", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"progam", "program", 2, 1},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 1, 2}, // Stops once over max
+		{"go", "go", 0, 0},
+		{"ab", "abcdef", 2, 3}, // Length difference alone exceeds max
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors specific to batch operations.
+var (
+	ErrEmptyBatch    = errors.New("no books given")
+	ErrDuplicateBook = errors.New("book is listed more than once")
+)
+
+// ItemError is the reason one book of a batch could not be processed.
+type ItemError struct {
+	BookID int
+	Err    error
+}
+
+func (e ItemError) Error() string {
+	return fmt.Sprintf("book %d: %v", e.BookID, e.Err)
+}
+
+func (e ItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is returned when a batch borrow or return is rejected. It lists
+// every book that failed validation; nothing in the batch was applied.
+// errors.Is matches it against the error of any item.
+type BatchError struct {
+	Items []ItemError
+}
+
+func (e *BatchError) Error() string {
+	reasons := make([]string, len(e.Items))
+	for i, item := range e.Items {
+		reasons[i] = item.Error()
+	}
+	return "batch rejected: " + strings.Join(reasons, "; ")
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Items))
+	for i, item := range e.Items {
+		errs[i] = item
+	}
+	return errs
+}
+
+// BorrowBooks lends several books to a member at once, at branchID (0 for
+// wherever each book is). Every book is validated before any is lent: if one
+// cannot be borrowed, none are and a *BatchError gives the reason for each
+// failing book. Problems with the member itself are returned directly.
+func (l *Library) BorrowBooks(bookIDs []int, memberID int, branchID int) error {
+	l.lock()
+	defer l.mu.Unlock()
+
+	member, exists := l.Members[memberID]
+	if !exists {
+		return ErrMemberNotFound
+	}
+	if member.Suspended {
+		return ErrMemberSuspended
+	}
+	if err := l.checkBatch(bookIDs, func(bookID int) error {
+		return l.checkBorrow(bookID, memberID, branchID)
+	}); err != nil {
+		return err
+	}
+
+	for _, bookID := range bookIDs {
+		l.borrow(bookID, memberID)
+	}
+	return nil
+}
+
+// ReturnBooks returns several books for a member at once, at branchID (0 for
+// where each was borrowed). Like BorrowBooks it is all-or-nothing.
+func (l *Library) ReturnBooks(bookIDs []int, memberID int, branchID int) error {
+	l.lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.Members[memberID]; !exists {
+		return ErrMemberNotFound
+	}
+	if _, exists := l.Branches[branchID]; branchID != 0 && !exists {
+		return ErrBranchNotFound
+	}
+	if err := l.checkBatch(bookIDs, func(bookID int) error {
+		return l.checkReturn(bookID, memberID, branchID)
+	}); err != nil {
+		return err
+	}
+
+	for _, bookID := range bookIDs {
+		l.giveBack(bookID, memberID, branchID)
+	}
+	return nil
+}
+
+// checkBatch runs check for every book, collecting the failures. Callers
+// hold l.mu.
+func (l *Library) checkBatch(bookIDs []int, check func(bookID int) error) error {
+	if len(bookIDs) == 0 {
+		return ErrEmptyBatch
+	}
+	var items []ItemError
+	seen := make(map[int]bool, len(bookIDs))
+	for _, bookID := range bookIDs {
+		if seen[bookID] {
+			items = append(items, ItemError{BookID: bookID, Err: ErrDuplicateBook})
+			continue
+		}
+		seen[bookID] = true
+		if err := check(bookID); err != nil {
+			items = append(items, ItemError{BookID: bookID, Err: err})
+		}
+	}
+	if len(items) > 0 {
+		return &BatchError{Items: items}
+	}
+	return nil
+}
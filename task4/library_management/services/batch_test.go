@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"library_management/models"
+	"testing"
+)
+
+func newBatchLibrary(t *testing.T) *Library {
+	t.Helper()
+	library, _ := newTestLibrary(t)
+	library.AddBook(models.Book{ID: 102, Title: "Concurrency in Go"})
+	library.AddBook(models.Book{ID: 103, Title: "Learning Go"})
+	return library
+}
+
+func TestBorrowBooksIsAllOrNothing(t *testing.T) {
+	library := newBatchLibrary(t)
+	if err := library.ReserveBook(103, 2); err != nil {
+		t.Fatalf("ReserveBook: %v", err)
+	}
+
+	err := library.BorrowBooks([]int{101, 102, 103, 999, 101}, 1, 0)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("BorrowBooks = %v, want a *BatchError", err)
+	}
+	want := map[int]error{103: ErrBookReserved, 999: ErrBookNotFound, 101: ErrDuplicateBook}
+	if len(batchErr.Items) != len(want) {
+		t.Fatalf("items = %v, want reasons for %v", batchErr.Items, want)
+	}
+	for _, item := range batchErr.Items {
+		if !errors.Is(item.Err, want[item.BookID]) {
+			t.Fatalf("book %d: %v, want %v", item.BookID, item.Err, want[item.BookID])
+		}
+	}
+	if !errors.Is(err, ErrBookReserved) {
+		t.Fatal("errors.Is does not match an item's error")
+	}
+
+	// Nothing was applied.
+	mustStatus(t, library, 101, models.StatusAvailable)
+	mustStatus(t, library, 102, models.StatusAvailable)
+	if loans := library.ListLoans(1); len(loans) != 0 {
+		t.Fatalf("member has %d loans after a rejected batch", len(loans))
+	}
+}
+
+func TestBorrowAndReturnBooks(t *testing.T) {
+	library := newBatchLibrary(t)
+	if err := library.BorrowBooks([]int{101, 102, 103}, 1, 0); err != nil {
+		t.Fatalf("BorrowBooks: %v", err)
+	}
+	if loans := library.ListLoans(1); len(loans) != 3 {
+		t.Fatalf("member has %d loans, want 3", len(loans))
+	}
+
+	// One book the member does not have rejects the whole return.
+	if err := library.BorrowBook(101, 2); !errors.Is(err, ErrBookBorrowed) {
+		t.Fatalf("BorrowBook of a lent book = %v, want %v", err, ErrBookBorrowed)
+	}
+	err := library.ReturnBooks([]int{101, 102}, 2, 0)
+	if !errors.Is(err, ErrNotBorrowedByMember) {
+		t.Fatalf("ReturnBooks by another member = %v, want %v", err, ErrNotBorrowedByMember)
+	}
+	mustStatus(t, library, 101, models.StatusBorrowed)
+
+	if err := library.ReturnBooks([]int{101, 102}, 1, 0); err != nil {
+		t.Fatalf("ReturnBooks: %v", err)
+	}
+	mustStatus(t, library, 101, models.StatusAvailable)
+	mustStatus(t, library, 102, models.StatusAvailable)
+	if loans := library.ListLoans(1); len(loans) != 1 || loans[0].BookID != 103 {
+		t.Fatalf("loans = %+v, want only book 103", loans)
+	}
+}
+
+func TestBatchMemberErrors(t *testing.T) {
+	library := newBatchLibrary(t)
+	if err := library.BorrowBooks([]int{101}, 42, 0); !errors.Is(err, ErrMemberNotFound) {
+		t.Fatalf("BorrowBooks for unknown member = %v, want %v", err, ErrMemberNotFound)
+	}
+	if err := library.SetMemberSuspended(1, true); err != nil {
+		t.Fatalf("SetMemberSuspended: %v", err)
+	}
+	if err := library.BorrowBooks([]int{101, 102}, 1, 0); !errors.Is(err, ErrMemberSuspended) {
+		t.Fatalf("BorrowBooks for suspended member = %v, want %v", err, ErrMemberSuspended)
+	}
+	if err := library.BorrowBooks(nil, 2, 0); !errors.Is(err, ErrEmptyBatch) {
+		t.Fatalf("BorrowBooks with no books = %v, want %v", err, ErrEmptyBatch)
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"library_management/events"
+	"library_management/models"
+	"sort"
+)
+
+// DefaultBranch is the branch every library starts with. Books added without
+// a home branch belong to it.
+const DefaultBranch = 1
+
+// AddBranch adds a branch. Adding a branch whose ID is already in use
+// returns ErrBranchExists.
+func (l *Library) AddBranch(branch models.Branch) error {
+	l.lock()
+	defer l.mu.Unlock()
+	if _, exists := l.Branches[branch.ID]; exists {
+		return ErrBranchExists
+	}
+	l.Branches[branch.ID] = branch
+	return nil
+}
+
+// ListBranches returns all branches ordered by ID.
+func (l *Library) ListBranches() []models.Branch {
+	l.lock()
+	defer l.mu.Unlock()
+
+	branches := make([]models.Branch, 0, len(l.Branches))
+	for _, branch := range l.Branches {
+		branches = append(branches, branch)
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].ID < branches[j].ID })
+	return branches
+}
+
+// TransferBook sends an available book to another branch. The book is In
+// Transit, and cannot be borrowed or reserved, until ReceiveBook is called at
+// the destination.
+func (l *Library) TransferBook(bookID int, toBranchID int) error {
+	l.lock()
+	defer l.mu.Unlock()
+
+	book, exists := l.Books[bookID]
+	if !exists {
+		return ErrBookNotFound
+	}
+	if _, exists := l.Branches[toBranchID]; !exists {
+		return ErrBranchNotFound
+	}
+	if book.Status == models.StatusInTransit {
+		return ErrBookInTransit
+	}
+	if book.Status != models.StatusAvailable {
+		return &models.TransitionError{From: book.Status, To: models.StatusInTransit}
+	}
+	if book.Branch == toBranchID {
+		return nil
+	}
+	l.startTransfer(book, toBranchID)
+	return nil
+}
+
+// ReceiveBook completes a transfer when the book arrives at branchID, which
+// must be its destination. A book sent for a reservation is held for the
+// member from now on; any other book becomes Available.
+func (l *Library) ReceiveBook(bookID int, branchID int) error {
+	l.lock()
+	defer l.mu.Unlock()
+
+	book, exists := l.Books[bookID]
+	if !exists {
+		return ErrBookNotFound
+	}
+	if _, exists := l.Branches[branchID]; !exists {
+		return ErrBranchNotFound
+	}
+	if book.Status != models.StatusInTransit {
+		return ErrNotInTransit
+	}
+	if book.Destination != branchID {
+		return ErrWrongBranch
+	}
+
+	book.Branch = branchID
+	book.Destination = 0
+	l.publishTransfer(events.TransferCompleted, book, branchID)
+	if book.ReservedBy != 0 {
+		l.holdBook(book)
+		return nil
+	}
+	book.Status = models.StatusAvailable
+	l.Books[bookID] = book
+	return nil
+}
+
+// startTransfer puts the book In Transit to branchID. Callers hold l.mu and
+// have checked that the book may move.
+func (l *Library) startTransfer(book models.Book, branchID int) {
+	book.Status = models.StatusInTransit
+	book.Destination = branchID
+	l.Books[book.ID] = book
+	l.publishTransfer(events.TransferStarted, book, branchID)
+}
+
+// checkBranch verifies that a book can be handed out at branchID; 0 means
+// wherever the book is. Callers hold l.mu.
+func (l *Library) checkBranch(book models.Book, branchID int) error {
+	if book.Status == models.StatusInTransit {
+		return ErrBookInTransit
+	}
+	if branchID == 0 {
+		return nil
+	}
+	if _, exists := l.Branches[branchID]; !exists {
+		return ErrBranchNotFound
+	}
+	if book.Branch != branchID {
+		return ErrWrongBranch
+	}
+	return nil
+}
+
+// publishTransfer emits a transfer event; callers hold l.mu.
+func (l *Library) publishTransfer(t events.Type, book models.Book, branchID int) {
+	l.bus.Publish(events.Event{
+		Type:     t,
+		BookID:   book.ID,
+		MemberID: book.ReservedBy,
+		Branch:   branchID,
+		Time:     l.clock.Now(),
+	})
+}
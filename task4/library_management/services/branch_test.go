@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"library_management/events"
+	"library_management/models"
+	"testing"
+	"time"
+)
+
+const eastBranch = 2
+
+func newBranchLibrary(t *testing.T) (*Library, func(time.Duration)) {
+	t.Helper()
+	library, fake := newTestLibrary(t)
+	library.AddBranch(models.Branch{ID: eastBranch, Name: "East"})
+	return library, fake.Advance
+}
+
+func TestBooksStartAtHomeBranch(t *testing.T) {
+	library, _ := newBranchLibrary(t)
+	library.AddBook(models.Book{ID: 102, Title: "Concurrency in Go", HomeBranch: eastBranch})
+
+	for id, want := range map[int]int{101: DefaultBranch, 102: eastBranch} {
+		book, _ := library.GetBook(id)
+		if book.HomeBranch != want || book.Branch != want {
+			t.Fatalf("book %d home/current branch = %d/%d, want %d", id, book.HomeBranch, book.Branch, want)
+		}
+	}
+	if err := library.BorrowBookAt(102, 1, DefaultBranch); !errors.Is(err, ErrWrongBranch) {
+		t.Fatalf("BorrowBookAt at another branch = %v, want %v", err, ErrWrongBranch)
+	}
+	if err := library.BorrowBookAt(102, 1, 99); !errors.Is(err, ErrBranchNotFound) {
+		t.Fatalf("BorrowBookAt at unknown branch = %v, want %v", err, ErrBranchNotFound)
+	}
+	if err := library.BorrowBookAt(102, 1, eastBranch); err != nil {
+		t.Fatalf("BorrowBookAt at the book's branch: %v", err)
+	}
+}
+
+func TestReturnAtAnotherBranchSendsBookHome(t *testing.T) {
+	library, _ := newBranchLibrary(t)
+	if err := library.BorrowBookAt(101, 1, DefaultBranch); err != nil {
+		t.Fatalf("BorrowBookAt: %v", err)
+	}
+	if err := library.ReturnBookAt(101, 1, eastBranch); err != nil {
+		t.Fatalf("ReturnBookAt: %v", err)
+	}
+	book := mustStatus(t, library, 101, models.StatusInTransit)
+	if book.Branch != eastBranch || book.Destination != DefaultBranch {
+		t.Fatalf("book at %d heading to %d, want at %d heading to %d", book.Branch, book.Destination, eastBranch, DefaultBranch)
+	}
+	if len(library.ListLoans(1)) != 0 {
+		t.Fatal("loan still open after return")
+	}
+
+	if err := library.BorrowBook(101, 2); !errors.Is(err, ErrBookInTransit) {
+		t.Fatalf("BorrowBook in transit = %v, want %v", err, ErrBookInTransit)
+	}
+	if err := library.ReceiveBook(101, eastBranch); !errors.Is(err, ErrWrongBranch) {
+		t.Fatalf("ReceiveBook at origin = %v, want %v", err, ErrWrongBranch)
+	}
+	if err := library.ReceiveBook(101, DefaultBranch); err != nil {
+		t.Fatalf("ReceiveBook: %v", err)
+	}
+	if book := mustStatus(t, library, 101, models.StatusAvailable); book.Branch != DefaultBranch {
+		t.Fatalf("book at branch %d after receiving, want %d", book.Branch, DefaultBranch)
+	}
+}
+
+func TestReservationForPickupAtAnotherBranch(t *testing.T) {
+	library, advance := newBranchLibrary(t)
+	stream, cancel := library.Subscribe()
+	defer cancel()
+
+	if err := library.ReserveBookAt(101, 1, eastBranch); err != nil {
+		t.Fatalf("ReserveBookAt: %v", err)
+	}
+	book := mustStatus(t, library, 101, models.StatusInTransit)
+	if book.ReservedBy != 1 || book.Destination != eastBranch {
+		t.Fatalf("book = %+v, want reserved by 1 and heading to %d", book, eastBranch)
+	}
+
+	// The hold does not start until the book arrives.
+	advance(2 * ReservationHold)
+	mustStatus(t, library, 101, models.StatusInTransit)
+	if err := library.ReserveBook(101, 2); !errors.Is(err, ErrBookNotAvailable) {
+		t.Fatalf("ReserveBook in transit = %v, want %v", err, ErrBookNotAvailable)
+	}
+
+	if err := library.ReceiveBook(101, eastBranch); err != nil {
+		t.Fatalf("ReceiveBook: %v", err)
+	}
+	if book := mustStatus(t, library, 101, models.StatusReserved); book.ReservedBy != 1 || book.Branch != eastBranch {
+		t.Fatalf("book = %+v, want reserved by 1 at branch %d", book, eastBranch)
+	}
+	if err := library.BorrowBookAt(101, 2, eastBranch); !errors.Is(err, ErrBookReserved) {
+		t.Fatalf("BorrowBookAt by other member = %v, want %v", err, ErrBookReserved)
+	}
+
+	// An uncollected hold expires and the book goes back home.
+	advance(ReservationHold)
+	if book := mustStatus(t, library, 101, models.StatusInTransit); book.Destination != DefaultBranch || book.ReservedBy != 0 {
+		t.Fatalf("book = %+v, want unreserved and heading to %d", book, DefaultBranch)
+	}
+
+	want := []events.Type{events.BookReserved, events.TransferStarted, events.TransferCompleted, events.ReservationExpired, events.TransferStarted}
+	for _, typ := range want {
+		select {
+		case e := <-stream:
+			if e.Type != typ {
+				t.Fatalf("event %s, want %s", e.Type, typ)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s event", typ)
+		}
+	}
+}
+
+func TestRemoveMemberReleasesBookInTransit(t *testing.T) {
+	library, _ := newBranchLibrary(t)
+	if err := library.ReserveBookAt(101, 1, eastBranch); err != nil {
+		t.Fatalf("ReserveBookAt: %v", err)
+	}
+	if err := library.RemoveMember(1); err != nil {
+		t.Fatalf("RemoveMember: %v", err)
+	}
+	if book := mustStatus(t, library, 101, models.StatusInTransit); book.ReservedBy != 0 {
+		t.Fatalf("ReservedBy = %d after removing the member, want 0", book.ReservedBy)
+	}
+
+	if err := library.ReceiveBook(101, eastBranch); err != nil {
+		t.Fatalf("ReceiveBook: %v", err)
+	}
+	if book := mustStatus(t, library, 101, models.StatusAvailable); book.Branch != eastBranch || book.ReservedBy != 0 {
+		t.Fatalf("book = %+v, want available at branch %d", book, eastBranch)
+	}
+	if err := library.BorrowBookAt(101, 2, eastBranch); err != nil {
+		t.Fatalf("BorrowBookAt after the reserver was removed: %v", err)
+	}
+	if issues := library.CheckIntegrity(false); len(issues) != 0 {
+		t.Fatalf("integrity issues: %v", issues)
+	}
+}
+
+func TestTransferBook(t *testing.T) {
+	library, _ := newBranchLibrary(t)
+	if err := library.TransferBook(101, 99); !errors.Is(err, ErrBranchNotFound) {
+		t.Fatalf("TransferBook to unknown branch = %v, want %v", err, ErrBranchNotFound)
+	}
+	if err := library.TransferBook(101, eastBranch); err != nil {
+		t.Fatalf("TransferBook: %v", err)
+	}
+	if err := library.TransferBook(101, DefaultBranch); !errors.Is(err, ErrBookInTransit) {
+		t.Fatalf("second TransferBook = %v, want %v", err, ErrBookInTransit)
+	}
+	if err := library.ReceiveBook(101, eastBranch); err != nil {
+		t.Fatalf("ReceiveBook: %v", err)
+	}
+	if err := library.ReceiveBook(101, eastBranch); !errors.Is(err, ErrNotInTransit) {
+		t.Fatalf("second ReceiveBook = %v, want %v", err, ErrNotInTransit)
+	}
+	if book := mustStatus(t, library, 101, models.StatusAvailable); book.Branch != eastBranch || book.HomeBranch != DefaultBranch {
+		t.Fatalf("book = %+v, want at branch %d with home %d", book, eastBranch, DefaultBranch)
+	}
+	if issues := library.CheckIntegrity(false); len(issues) != 0 {
+		t.Fatalf("integrity issues: %v", issues)
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"fmt"
+	"library_management/models"
+	"sort"
+)
+
+// IntegrityIssue describes a disagreement between books and members found by
+// CheckIntegrity.
+type IntegrityIssue struct {
+	BookID   int
+	MemberID int // 0 when the issue concerns only the book
+	Problem  string
+	Repaired bool
+}
+
+func (i IntegrityIssue) String() string {
+	s := fmt.Sprintf("book %d", i.BookID)
+	if i.MemberID != 0 {
+		s += fmt.Sprintf(", member %d", i.MemberID)
+	}
+	s += ": " + i.Problem
+	if i.Repaired {
+		s += " (repaired)"
+	}
+	return s
+}
+
+// CheckIntegrity verifies that books and members agree:
+//   - every loan references an existing book that is Borrowed,
+//   - every Borrowed book has exactly one loan,
+//   - a book has a ReservedBy member only when it is Reserved (or In Transit
+//     to a pickup branch), and that member exists,
+//   - a book's home and current branches exist, and it has an existing
+//     destination exactly when it is In Transit.
+//
+// With repair set, each issue is fixed as it is found, treating the book's
+// status as authoritative: loans for missing or non-borrowed books are
+// dropped, a Borrowed book without a loan becomes Available, only the oldest
+// of several loans is kept, broken reservations are cleared, unknown branches
+// fall back to the home branch (or DefaultBranch), and a book in transit to an
+// unknown branch is sent home.
+func (l *Library) CheckIntegrity(repair bool) []IntegrityIssue {
+	l.lock()
+	defer l.mu.Unlock()
+
+	issues := []IntegrityIssue{}
+	report := func(bookID, memberID int, format string, args ...any) {
+		issues = append(issues, IntegrityIssue{
+			BookID:   bookID,
+			MemberID: memberID,
+			Problem:  fmt.Sprintf(format, args...),
+			Repaired: repair,
+		})
+	}
+
+	// Every loan must point at an existing, borrowed book, once per member.
+	holders := make(map[int][]models.Loan) // book ID -> loans of that book
+	for _, memberID := range sortedKeys(l.Members) {
+		member := l.Members[memberID]
+		kept := member.Loans[:0:0]
+		seen := make(map[int]bool)
+		for _, loan := range member.Loans {
+			book, exists := l.Books[loan.BookID]
+			switch {
+			case !exists:
+				report(loan.BookID, memberID, "loan references a book that does not exist")
+				continue
+			case book.Status != models.StatusBorrowed:
+				report(loan.BookID, memberID, "loan exists but the book is %s", book.Status)
+				continue
+			case seen[loan.BookID]:
+				report(loan.BookID, memberID, "member has the same book on loan twice")
+				continue
+			}
+			if loan.MemberID != memberID {
+				report(loan.BookID, memberID, "loan is recorded for member %d", loan.MemberID)
+				loan.MemberID = memberID
+			}
+			seen[loan.BookID] = true
+			kept = append(kept, loan)
+			holders[loan.BookID] = append(holders[loan.BookID], loan)
+		}
+		if repair {
+			member.Loans = kept
+			l.Members[memberID] = member
+		}
+	}
+
+	for _, bookID := range sortedKeys(l.Books) {
+		book := l.Books[bookID]
+
+		// Every borrowed book must have exactly one loan.
+		if book.Status == models.StatusBorrowed {
+			loans := holders[bookID]
+			switch {
+			case len(loans) == 0:
+				report(bookID, 0, "book is Borrowed but no member has it on loan")
+				if repair {
+					book.Status = models.StatusAvailable
+				}
+			case len(loans) > 1:
+				sort.Slice(loans, func(i, j int) bool { return loans[i].BorrowedAt.Before(loans[j].BorrowedAt) })
+				for _, extra := range loans[1:] {
+					report(bookID, extra.MemberID, "book is also on loan to member %d", loans[0].MemberID)
+					if repair {
+						member := l.Members[extra.MemberID]
+						if i := loanIndex(member, bookID); i >= 0 {
+							member.Loans = append(member.Loans[:i], member.Loans[i+1:]...)
+							l.Members[extra.MemberID] = member
+						}
+					}
+				}
+			}
+		}
+
+		// Reservations must belong to an existing member.
+		if book.Status == models.StatusReserved || (book.Status == models.StatusInTransit && book.ReservedBy != 0) {
+			if _, exists := l.Members[book.ReservedBy]; !exists {
+				report(bookID, book.ReservedBy, "book is reserved by a member who does not exist")
+				if repair {
+					if book.Status == models.StatusReserved {
+						book.Status = models.StatusAvailable
+					}
+					book.ReservedBy = 0
+				}
+			}
+		} else if book.ReservedBy != 0 {
+			report(bookID, book.ReservedBy, "book is %s but still records a reservation", book.Status)
+			if repair {
+				book.ReservedBy = 0
+			}
+		}
+
+		// Books must be at known branches; only books in transit have a destination.
+		if _, exists := l.Branches[book.HomeBranch]; !exists {
+			report(bookID, 0, "home branch %d does not exist", book.HomeBranch)
+			if repair {
+				book.HomeBranch = DefaultBranch
+			}
+		}
+		if _, exists := l.Branches[book.Branch]; !exists {
+			report(bookID, 0, "book is at branch %d, which does not exist", book.Branch)
+			if repair {
+				book.Branch = book.HomeBranch
+			}
+		}
+		if book.Status == models.StatusInTransit {
+			if _, exists := l.Branches[book.Destination]; !exists {
+				report(bookID, 0, "book is in transit to branch %d, which does not exist", book.Destination)
+				if repair {
+					book.Destination = book.HomeBranch
+				}
+			}
+		} else if book.Destination != 0 {
+			report(bookID, 0, "book is %s but still records a transfer to branch %d", book.Status, book.Destination)
+			if repair {
+				book.Destination = 0
+			}
+		}
+
+		if repair {
+			l.Books[bookID] = book
+		}
+	}
+	return issues
+}
+
+// sortedKeys returns the keys of a map keyed by ID in ascending order, so
+// integrity reports are deterministic.
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"library_management/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	// Each corrupt function puts the library into a state its public
+	// methods never produce.
+	tests := []struct {
+		name    string
+		corrupt func(l *Library)
+		issues  [][2]int // Book and member ID of each issue
+		check   func(t *testing.T, l *Library)
+	}{
+		{
+			name: "loan with no book",
+			corrupt: func(l *Library) {
+				member := l.Members[1]
+				member.Loans = append(member.Loans, models.Loan{BookID: 999, MemberID: 1})
+				l.Members[1] = member
+			},
+			issues: [][2]int{{999, 1}},
+			check: func(t *testing.T, l *Library) {
+				if loans := l.ListLoans(1); len(loans) != 0 {
+					t.Errorf("member 1 loans = %+v, want none", loans)
+				}
+			},
+		},
+		{
+			name: "loan for a book that is not borrowed",
+			corrupt: func(l *Library) {
+				member := l.Members[2]
+				member.Loans = append(member.Loans, models.Loan{BookID: 101, MemberID: 2})
+				l.Members[2] = member
+			},
+			issues: [][2]int{{101, 2}},
+			check: func(t *testing.T, l *Library) {
+				mustStatus(t, l, 101, models.StatusAvailable)
+				if loans := l.ListLoans(2); len(loans) != 0 {
+					t.Errorf("member 2 loans = %+v, want none", loans)
+				}
+			},
+		},
+		{
+			name: "borrowed book with no loan",
+			corrupt: func(l *Library) {
+				book := l.Books[101]
+				book.Status = models.StatusBorrowed
+				l.Books[101] = book
+			},
+			issues: [][2]int{{101, 0}},
+			check: func(t *testing.T, l *Library) {
+				mustStatus(t, l, 101, models.StatusAvailable)
+			},
+		},
+		{
+			name: "double loan keeps the oldest",
+			corrupt: func(l *Library) {
+				if err := l.BorrowBook(101, 1); err != nil {
+					panic(err)
+				}
+				member := l.Members[2]
+				member.Loans = append(member.Loans, models.Loan{BookID: 101, MemberID: 2, BorrowedAt: start.Add(time.Hour)})
+				l.Members[2] = member
+			},
+			issues: [][2]int{{101, 2}},
+			check: func(t *testing.T, l *Library) {
+				mustStatus(t, l, 101, models.StatusBorrowed)
+				if loans := l.ListLoans(1); len(loans) != 1 {
+					t.Errorf("member 1 has %d loans, want 1", len(loans))
+				}
+				if loans := l.ListLoans(2); len(loans) != 0 {
+					t.Errorf("member 2 loans = %+v, want none", loans)
+				}
+			},
+		},
+		{
+			name: "reservation by a member who does not exist",
+			corrupt: func(l *Library) {
+				book := l.Books[101]
+				book.Status = models.StatusReserved
+				book.ReservedBy = 9
+				l.Books[101] = book
+			},
+			issues: [][2]int{{101, 9}},
+			check: func(t *testing.T, l *Library) {
+				if book := mustStatus(t, l, 101, models.StatusAvailable); book.ReservedBy != 0 {
+					t.Errorf("ReservedBy = %d, want 0", book.ReservedBy)
+				}
+			},
+		},
+		{
+			name: "bad branches",
+			corrupt: func(l *Library) {
+				book := l.Books[101]
+				book.HomeBranch = 8
+				book.Branch = 9
+				book.Destination = 9
+				l.Books[101] = book
+			},
+			// Unknown home branch, unknown current branch, stale destination.
+			issues: [][2]int{{101, 0}, {101, 0}, {101, 0}},
+			check: func(t *testing.T, l *Library) {
+				book, _ := l.GetBook(101)
+				if book.HomeBranch != DefaultBranch || book.Branch != DefaultBranch || book.Destination != 0 {
+					t.Errorf("book = %+v, want home and current branch %d and no destination", book, DefaultBranch)
+				}
+			},
+		},
+		{
+			name: "in transit to a branch that does not exist",
+			corrupt: func(l *Library) {
+				book := l.Books[101]
+				book.Status = models.StatusInTransit
+				book.Destination = 9
+				l.Books[101] = book
+			},
+			issues: [][2]int{{101, 0}},
+			check: func(t *testing.T, l *Library) {
+				if book := mustStatus(t, l, 101, models.StatusInTransit); book.Destination != DefaultBranch {
+					t.Errorf("destination = %d, want home branch %d", book.Destination, DefaultBranch)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library, _ := newTestLibrary(t)
+			if issues := library.CheckIntegrity(false); len(issues) != 0 {
+				t.Fatalf("issues before corruption: %v", issues)
+			}
+			tt.corrupt(library)
+			before := snapshotLibrary(library)
+
+			issues := library.CheckIntegrity(false)
+			if got := issueIDs(issues); !reflect.DeepEqual(got, tt.issues) {
+				t.Fatalf("issues = %v, want book/member %v", issues, tt.issues)
+			}
+			for _, issue := range issues {
+				if issue.Repaired {
+					t.Errorf("issue %v marked repaired by a check", issue)
+				}
+			}
+			if after := snapshotLibrary(library); !reflect.DeepEqual(after, before) {
+				t.Fatal("CheckIntegrity without repair changed the library")
+			}
+
+			repaired := library.CheckIntegrity(true)
+			if got := issueIDs(repaired); !reflect.DeepEqual(got, tt.issues) {
+				t.Fatalf("repaired issues = %v, want book/member %v", repaired, tt.issues)
+			}
+			for _, issue := range repaired {
+				if !issue.Repaired {
+					t.Errorf("issue %v not marked repaired", issue)
+				}
+			}
+			if issues := library.CheckIntegrity(false); len(issues) != 0 {
+				t.Fatalf("issues after repair: %v", issues)
+			}
+			tt.check(t, library)
+		})
+	}
+}
+
+func issueIDs(issues []IntegrityIssue) [][2]int {
+	ids := make([][2]int, len(issues))
+	for i, issue := range issues {
+		ids[i] = [2]int{issue.BookID, issue.MemberID}
+	}
+	return ids
+}
+
+// snapshotLibrary copies the books and members so later changes can be detected.
+func snapshotLibrary(l *Library) [2]any {
+	return [2]any{l.ListBooks(), l.ListMembers()}
+}
@@ -3,136 +3,385 @@ package services
 import (
 	"errors"
 	"fmt"
+	"library_management/clock"
+	"library_management/events"
 	"library_management/models"
+	"library_management/notifications"
+	"library_management/recommend"
+	"library_management/search"
+	"log"
+	"sort"
 	"sync"
 	"time"
 )
 
+const (
+	// LoanPeriod is how long a member may keep a borrowed book.
+	LoanPeriod = 14 * 24 * time.Hour
+	// DueSoonWindow is how long before the due date members are reminded.
+	DueSoonWindow = 2 * 24 * time.Hour
+	// ReservationHold is how long a reserved book is held before the
+	// reservation expires.
+	ReservationHold = 5 * time.Second
+)
+
+// Errors returned by library operations. Callers can match them with errors.Is.
+var (
+	ErrBookNotFound        = errors.New("book not found")
+	ErrBookExists          = errors.New("a book with this ID already exists")
+	ErrMemberNotFound      = errors.New("member not found")
+	ErrMemberExists        = errors.New("a member with this ID already exists")
+	ErrBookReserved        = errors.New("book is reserved by another member")
+	ErrBookBorrowed        = errors.New("book is already borrowed")
+	ErrBookNotAvailable    = errors.New("book is not available for reservation")
+	ErrNotBorrowedByMember = errors.New("this book is not borrowed by the member")
+	ErrBookOnLoan          = errors.New("book is currently borrowed")
+	ErrMemberSuspended     = errors.New("member is suspended")
+	ErrMemberHasLoans      = errors.New("member still has borrowed books")
+	ErrBranchNotFound      = errors.New("branch not found")
+	ErrBranchExists        = errors.New("a branch with this ID already exists")
+	ErrWrongBranch         = errors.New("book is not at this branch")
+	ErrBookInTransit       = errors.New("book is in transit between branches")
+	ErrNotInTransit        = errors.New("book is not in transit")
+)
+
 // LibraryManager defines methods for managing the library.
 type LibraryManager interface {
-	AddBook(book models.Book)
-	RemoveBook(bookID int)
+	AddBook(book models.Book) error
+	RemoveBook(bookID int) error
 	BorrowBook(bookID int, memberID int) error
+	BorrowBookAt(bookID int, memberID int, branchID int) error
 	ReturnBook(bookID int, memberID int) error
+	ReturnBookAt(bookID int, memberID int, branchID int) error
+	BorrowBooks(bookIDs []int, memberID int, branchID int) error
+	ReturnBooks(bookIDs []int, memberID int, branchID int) error
 	ListAvailableBooks() []models.Book
 	ListBorrowedBooks(memberID int) []models.Book
+	ListLoans(memberID int) []models.Loan
 	ReserveBook(bookID int, memberID int) error
-	AddMember(member models.Member)
+	ReserveBookAt(bookID int, memberID int, pickupBranchID int) error
+	AddBranch(branch models.Branch) error
+	ListBranches() []models.Branch
+	TransferBook(bookID int, toBranchID int) error
+	ReceiveBook(bookID int, branchID int) error
+	AddMember(member models.Member) error
+	UpdateMember(memberID int, name string) error
+	SetMemberSuspended(memberID int, suspended bool) error
+	RemoveMember(memberID int) error
+	UpdateBookStatus(bookID int, status models.BookStatus) error
+	GetBook(bookID int) (models.Book, error)
+	ListBooks() []models.Book
+	GetMember(memberID int) (models.Member, error)
+	ListMembers() []models.Member
+	SearchBooks(query string) []models.Book
+	AlsoBorrowed(bookID int, limit int) ([]Recommendation, error)
+	RecommendFor(memberID int, limit int) ([]Recommendation, error)
+	Subscribe() (<-chan events.Event, func())
 }
 
 // Library implements LibraryManager.
 type Library struct {
-	Books   map[int]models.Book   // Keyed by book ID
-	Members map[int]models.Member // Keyed by member ID
-	mu      sync.Mutex            // Protects access to Books and Members
+	Books    map[int]models.Book   // Keyed by book ID
+	Members  map[int]models.Member // Keyed by member ID
+	Branches map[int]models.Branch // Keyed by branch ID
+	mu       sync.Mutex            // Protects access to Books, Members and Branches
+	index    *search.Index         // Title/author index maintained by AddBook and RemoveBook
+	bus      *events.Bus           // Publishes borrow, return and reservation events
+	history  *recommend.History    // Who has borrowed what, for recommendations
+	metrics  *libraryMetrics       // Operation counters, exposed by RegisterMetrics
+
+	notifier  notifications.Notifier     // Delivers member notifications (nil disables them)
+	prefs     *notifications.Preferences // Per-member notification opt-outs
+	reminders map[int]notifications.Kind // Last due-date reminder sent, keyed by book ID
+
+	clock clock.Clock          // Source of time for loans, holds and events
+	holds map[int]*pendingHold // Pending reservation expiries, keyed by book ID
 }
 
-// NewLibrary creates a new Library instance.
+// pendingHold is the expiry of one reservation. Its address identifies the
+// reservation to the expiry callback, which is created before the timer is.
+type pendingHold struct {
+	timer clock.Timer // Set under Library.mu once the timer is started
+}
+
+// NewLibrary creates a new Library instance that uses the wall clock.
 func NewLibrary() *Library {
+	return NewLibraryWithClock(clock.Real{})
+}
+
+// NewLibraryWithClock creates a Library that takes the time from c. Tests
+// pass a clock.Fake to expire reservations and loans without waiting.
+func NewLibraryWithClock(c clock.Clock) *Library {
 	return &Library{
-		Books:   make(map[int]models.Book),
-		Members: make(map[int]models.Member),
+		Books:    make(map[int]models.Book),
+		Members:  make(map[int]models.Member),
+		Branches: map[int]models.Branch{DefaultBranch: {ID: DefaultBranch, Name: "Main"}},
+		index:    search.NewIndex(),
+		bus:      events.NewBus(),
+		history:  recommend.NewHistory(),
+		metrics:  newLibraryMetrics(),
+
+		prefs:     notifications.NewPreferences(),
+		reminders: make(map[int]notifications.Kind),
+
+		clock: c,
+		holds: make(map[int]*pendingHold),
 	}
 }
 
-// AddBook adds a new book to the library.
-func (l *Library) AddBook(book models.Book) {
-	l.mu.Lock()
+// AddBook adds a new book to the library, shelved at its home branch.
+// Books without a home branch belong to DefaultBranch. Adding a book whose
+// ID is already in use returns ErrBookExists and leaves the catalog unchanged.
+func (l *Library) AddBook(book models.Book) error {
+	l.lock()
 	defer l.mu.Unlock()
-	book.Status = "Available"
+	if _, exists := l.Books[book.ID]; exists {
+		return ErrBookExists
+	}
+	book.Status = models.StatusAvailable
 	book.ReservedBy = 0
+	if book.HomeBranch == 0 {
+		book.HomeBranch = DefaultBranch
+	}
+	book.Branch = book.HomeBranch
+	book.Destination = 0
 	l.Books[book.ID] = book
+	l.index.Add(book.ID, book.Title, book.Author)
+	return nil
 }
 
 // RemoveBook removes a book from the library by its ID.
-func (l *Library) RemoveBook(bookID int) {
-	l.mu.Lock()
+// A book that is currently borrowed cannot be removed.
+func (l *Library) RemoveBook(bookID int) error {
+	l.lock()
 	defer l.mu.Unlock()
+
+	book, exists := l.Books[bookID]
+	if !exists {
+		return ErrBookNotFound
+	}
+	if book.Status == models.StatusBorrowed {
+		return ErrBookOnLoan
+	}
 	delete(l.Books, bookID)
+	l.index.Remove(bookID)
+	l.cancelHold(bookID)
+	return nil
 }
 
 // BorrowBook allows a member to borrow a book if it is available or reserved for them.
 func (l *Library) BorrowBook(bookID int, memberID int) error {
-	l.mu.Lock()
+	return l.BorrowBookAt(bookID, memberID, 0)
+}
+
+// BorrowBookAt is BorrowBook at a branch: the book must be shelved there.
+// A branchID of 0 borrows the book wherever it is.
+func (l *Library) BorrowBookAt(bookID int, memberID int, branchID int) error {
+	l.lock()
 	defer l.mu.Unlock()
 
+	if err := l.checkBorrow(bookID, memberID, branchID); err != nil {
+		return err
+	}
+	l.borrow(bookID, memberID)
+	return nil
+}
+
+// checkBorrow reports why a member could not borrow a book at a branch, or
+// nil if they can. Callers hold l.mu.
+func (l *Library) checkBorrow(bookID int, memberID int, branchID int) error {
 	book, exists := l.Books[bookID]
 	if !exists {
-		return errors.New("book not found")
+		return ErrBookNotFound
+	}
+	if err := l.checkBranch(book, branchID); err != nil {
+		return err
 	}
 
-	// If the book is reserved, only the member who reserved it can borrow it.
-	if book.Status == "Reserved" {
+	switch book.Status {
+	case models.StatusReserved:
+		// If the book is reserved, only the member who reserved it can borrow it.
+		// Reservation will be cleared upon borrowing.
 		if book.ReservedBy != memberID {
-			return errors.New("book is reserved by another member")
+			return ErrBookReserved
 		}
-		// Reservation will be cleared upon borrowing.
+	case models.StatusBorrowed:
+		return ErrBookBorrowed
 	}
-
-	if book.Status != "Available" && book.Status != "Reserved" {
-		return errors.New("book is already borrowed")
+	if err := book.Status.ValidateTransition(models.StatusBorrowed); err != nil {
+		return err
 	}
 
-	// Update book status to Borrowed.
-	book.Status = "Borrowed"
-	book.ReservedBy = 0
-	l.Books[bookID] = book
-
 	member, exists := l.Members[memberID]
 	if !exists {
-		return errors.New("member not found")
+		return ErrMemberNotFound
+	}
+	if member.Suspended {
+		return ErrMemberSuspended
+	}
+	return nil
+}
+
+// borrow lends a book to a member. Callers hold l.mu and have called
+// checkBorrow.
+func (l *Library) borrow(bookID int, memberID int) {
+	book := l.Books[bookID]
+	member := l.Members[memberID]
+
+	if book.Status == models.StatusReserved {
+		l.metrics.reservations.Inc(ReservationFulfilled)
 	}
 
-	member.BorrowedBooks = append(member.BorrowedBooks, book)
+	// Update book status to Borrowed and record the loan.
+	book.Status = models.StatusBorrowed
+	book.ReservedBy = 0
+	l.Books[bookID] = book
+	l.cancelHold(bookID)
+
+	now := l.clock.Now()
+	member.Loans = append(member.Loans, models.Loan{
+		BookID:     bookID,
+		MemberID:   memberID,
+		BorrowedAt: now,
+		DueDate:    now.Add(LoanPeriod),
+	})
 	l.Members[memberID] = member
+	l.history.Record(memberID, bookID)
+	l.metrics.borrows.Inc()
 
-	return nil
+	l.publish(events.BookBorrowed, bookID, memberID)
 }
 
 // ReturnBook allows a member to return a borrowed book.
 func (l *Library) ReturnBook(bookID int, memberID int) error {
-	l.mu.Lock()
+	return l.ReturnBookAt(bookID, memberID, 0)
+}
+
+// ReturnBookAt is ReturnBook at a branch. A book returned away from its home
+// branch is sent home: it goes In Transit until ReceiveBook is called at the
+// home branch. A branchID of 0 returns the book where it was borrowed.
+func (l *Library) ReturnBookAt(bookID int, memberID int, branchID int) error {
+	l.lock()
 	defer l.mu.Unlock()
 
+	if err := l.checkReturn(bookID, memberID, branchID); err != nil {
+		return err
+	}
+	l.giveBack(bookID, memberID, branchID)
+	return nil
+}
+
+// checkReturn reports why a member could not return a book at a branch, or
+// nil if they can. Callers hold l.mu.
+func (l *Library) checkReturn(bookID int, memberID int, branchID int) error {
 	book, exists := l.Books[bookID]
 	if !exists {
-		return errors.New("book not found")
+		return ErrBookNotFound
+	}
+	if _, exists := l.Branches[branchID]; branchID != 0 && !exists {
+		return ErrBranchNotFound
 	}
 
 	member, exists := l.Members[memberID]
 	if !exists {
-		return errors.New("member not found")
+		return ErrMemberNotFound
 	}
 
 	// Check if the member has borrowed this book.
-	found := false
-	for i, b := range member.BorrowedBooks {
-		if b.ID == bookID {
-			// Remove the book from the member's slice.
-			member.BorrowedBooks = append(member.BorrowedBooks[:i], member.BorrowedBooks[i+1:]...)
-			found = true
-			break
-		}
-	}
-	if !found {
-		return errors.New("this book is not borrowed by the member")
+	if loanIndex(member, bookID) < 0 {
+		return ErrNotBorrowedByMember
 	}
+	return book.Status.ValidateTransition(models.StatusAvailable)
+}
 
-	// Update the book status.
-	book.Status = "Available"
+// giveBack closes a member's loan of a book returned at branchID. Callers
+// hold l.mu and have called checkReturn.
+func (l *Library) giveBack(bookID int, memberID int, branchID int) {
+	book := l.Books[bookID]
+	member := l.Members[memberID]
+	i := loanIndex(member, bookID)
+
+	// Update the book status and close the loan.
+	book.Status = models.StatusAvailable
+	if branchID != 0 {
+		book.Branch = branchID
+	}
 	l.Books[bookID] = book
+	member.Loans = append(member.Loans[:i], member.Loans[i+1:]...)
+	delete(l.reminders, bookID)
 	l.Members[memberID] = member
+	l.metrics.returns.Inc()
+	l.publish(events.BookReturned, bookID, memberID)
+
+	if book.Branch != book.HomeBranch {
+		l.startTransfer(book, book.HomeBranch)
+	}
+}
+
+// UpdateBookStatus lets a librarian move a book through its lifecycle, e.g.
+// mark it lost or damaged, send it to repair, or withdraw it. Borrowing,
+// returning, reserving and transfers have their own operations, so Borrowed,
+// Reserved and In Transit cannot be set here and a borrowed book cannot be
+// made Available. Marking a borrowed book lost or damaged closes the member's
+// loan; any transfer is dropped. A reservation, whether the book is held or
+// still on its way to the pickup branch, is cancelled and the member told.
+// Invalid changes return an error matching models.ErrInvalidTransition.
+func (l *Library) UpdateBookStatus(bookID int, status models.BookStatus) error {
+	l.lock()
+	defer l.mu.Unlock()
+
+	book, exists := l.Books[bookID]
+	if !exists {
+		return ErrBookNotFound
+	}
+	if status == models.StatusBorrowed || status == models.StatusReserved || status == models.StatusInTransit ||
+		(book.Status == models.StatusBorrowed && status == models.StatusAvailable) {
+		return &models.TransitionError{From: book.Status, To: status}
+	}
+	if err := book.Status.ValidateTransition(status); err != nil {
+		return err
+	}
+
+	if book.Status == models.StatusBorrowed {
+		// Close the loan of whoever has the book.
+		for id, member := range l.Members {
+			if i := loanIndex(member, bookID); i >= 0 {
+				member.Loans = append(member.Loans[:i], member.Loans[i+1:]...)
+				l.Members[id] = member
+			}
+		}
+		delete(l.reminders, bookID)
+	}
+
+	reservedBy := book.ReservedBy
+	book.Status = status
+	book.ReservedBy = 0
+	book.Destination = 0
+	l.Books[bookID] = book
+	l.cancelHold(bookID)
+	if reservedBy != 0 {
+		l.metrics.reservations.Inc(ReservationCancelled)
+		l.publish(events.ReservationCancelled, bookID, reservedBy)
+		l.notify(reservedBy, notifications.ReservationCancelled, book,
+			fmt.Sprintf("Your reservation of %q was cancelled because the book is now %s.", book.Title, status))
+	}
+	l.bus.Publish(events.Event{
+		Type:   events.BookStatusChanged,
+		BookID: bookID,
+		Status: string(status),
+		Time:   l.clock.Now(),
+	})
 	return nil
 }
 
 // ListAvailableBooks lists all books that are currently available.
 func (l *Library) ListAvailableBooks() []models.Book {
-	l.mu.Lock()
+	l.lock()
 	defer l.mu.Unlock()
 
 	available := []models.Book{}
 	for _, book := range l.Books {
-		if book.Status == "Available" {
+		if book.Status == models.StatusAvailable {
 			available = append(available, book)
 		}
 	}
@@ -141,75 +390,490 @@ func (l *Library) ListAvailableBooks() []models.Book {
 
 // ListBorrowedBooks lists all books borrowed by a specific member.
 func (l *Library) ListBorrowedBooks(memberID int) []models.Book {
-	l.mu.Lock()
+	l.lock()
 	defer l.mu.Unlock()
 
 	member, exists := l.Members[memberID]
 	if !exists {
 		return []models.Book{}
 	}
-	return member.BorrowedBooks
+	books := make([]models.Book, 0, len(member.Loans))
+	for _, loan := range member.Loans {
+		if book, exists := l.Books[loan.BookID]; exists {
+			books = append(books, book)
+		}
+	}
+	return books
+}
+
+// ListLoans returns a member's current loans, oldest first.
+func (l *Library) ListLoans(memberID int) []models.Loan {
+	l.lock()
+	defer l.mu.Unlock()
+
+	member, exists := l.Members[memberID]
+	if !exists {
+		return []models.Loan{}
+	}
+	return append([]models.Loan{}, member.Loans...)
+}
+
+// OpenLoans returns every member's current loans, by due date.
+func (l *Library) OpenLoans() []models.Loan {
+	l.lock()
+	defer l.mu.Unlock()
+
+	loans := []models.Loan{}
+	for _, member := range l.Members {
+		loans = append(loans, member.Loans...)
+	}
+	sort.Slice(loans, func(i, j int) bool {
+		if !loans[i].DueDate.Equal(loans[j].DueDate) {
+			return loans[i].DueDate.Before(loans[j].DueDate)
+		}
+		return loans[i].BookID < loans[j].BookID
+	})
+	return loans
+}
+
+// Now returns the current time on the library's clock.
+func (l *Library) Now() time.Time {
+	return l.clock.Now()
+}
+
+// loanIndex returns the position of the member's loan of a book, or -1.
+func loanIndex(member models.Member, bookID int) int {
+	for i, loan := range member.Loans {
+		if loan.BookID == bookID {
+			return i
+		}
+	}
+	return -1
 }
 
-// ReserveBook reserves a book for a member if it is available.
-// It also starts a timer that will auto-cancel the reservation after 5 seconds if not borrowed.
+// ReserveBook reserves a book for a member if it is available, for pickup at
+// the branch where it is. The reservation is cancelled automatically if the
+// book is not borrowed within ReservationHold.
 func (l *Library) ReserveBook(bookID int, memberID int) error {
-	l.mu.Lock()
+	return l.ReserveBookAt(bookID, memberID, 0)
+}
+
+// ReserveBookAt reserves a book for pickup at another branch. The book is
+// sent there In Transit, and the hold starts when ReceiveBook is called at the
+// pickup branch. A pickupBranchID of 0, or the book's current branch, behaves
+// like ReserveBook.
+func (l *Library) ReserveBookAt(bookID int, memberID int, pickupBranchID int) error {
+	l.lock()
 	defer l.mu.Unlock()
 
+	if err := l.reserve(bookID, memberID, pickupBranchID); err != nil {
+		l.metrics.reservations.Inc(ReservationRejected)
+		return err
+	}
+	l.metrics.reservations.Inc(ReservationPlaced)
+	return nil
+}
+
+// reserve does the work of ReserveBookAt. Callers hold l.mu.
+func (l *Library) reserve(bookID int, memberID int, pickupBranchID int) error {
 	book, exists := l.Books[bookID]
 	if !exists {
-		return errors.New("book not found")
+		return ErrBookNotFound
 	}
-	if book.Status != "Available" {
-		return errors.New("book is not available for reservation")
+	member, exists := l.Members[memberID]
+	if !exists {
+		return ErrMemberNotFound
+	}
+	if member.Suspended {
+		return ErrMemberSuspended
+	}
+	if _, exists := l.Branches[pickupBranchID]; pickupBranchID != 0 && !exists {
+		return ErrBranchNotFound
+	}
+	if book.Status == models.StatusInTransit {
+		return ErrBookNotAvailable
+	}
+	if err := book.Status.ValidateTransition(models.StatusReserved); err != nil {
+		// Say why the book is unavailable; errors.Is still matches
+		// ErrBookNotAvailable.
+		switch book.Status {
+		case models.StatusReserved:
+			return fmt.Errorf("%w: %w", ErrBookNotAvailable, ErrBookReserved)
+		case models.StatusBorrowed:
+			return fmt.Errorf("%w: %w", ErrBookNotAvailable, ErrBookBorrowed)
+		}
+		return err
 	}
 
-	// Reserve the book.
-	book.Status = "Reserved"
 	book.ReservedBy = memberID
-	l.Books[bookID] = book
-
-	// Start auto-cancellation in a separate goroutine.
-	go l.autoCancelReservation(bookID, memberID)
-
+	l.publish(events.BookReserved, bookID, memberID)
+	if pickupBranchID != 0 && pickupBranchID != book.Branch {
+		l.startTransfer(book, pickupBranchID)
+		return nil
+	}
+	l.holdBook(book)
 	return nil
 }
 
-// autoCancelReservation will cancel a reservation if the book is not borrowed within 5 seconds.
-func (l *Library) autoCancelReservation(bookID int, memberID int) {
-	timer := time.NewTimer(5 * time.Second)
-	<-timer.C
+// holdBook marks the book Reserved for book.ReservedBy at its current branch,
+// tells the member it is ready and schedules the reservation's expiry.
+// Callers hold l.mu.
+func (l *Library) holdBook(book models.Book) {
+	memberID := book.ReservedBy
+	book.Status = models.StatusReserved
+	l.Books[book.ID] = book
+	l.notify(memberID, notifications.ReservationReady, book,
+		fmt.Sprintf("%q is being held for you. Borrow it within %s or the hold will expire.", book.Title, ReservationHold))
+
+	// Cancel the reservation if the book is not borrowed in time.
+	bookID := book.ID
+	l.cancelHold(bookID)
+	hold := &pendingHold{}
+	l.holds[bookID] = hold
+	hold.timer = l.clock.AfterFunc(ReservationHold, func() {
+		l.autoCancelReservation(bookID, memberID, hold)
+	})
+}
 
-	l.mu.Lock()
+// cancelHold stops the pending expiry of a reservation on bookID, if any.
+// Callers hold l.mu.
+func (l *Library) cancelHold(bookID int) {
+	if hold, ok := l.holds[bookID]; ok {
+		hold.timer.Stop()
+		delete(l.holds, bookID)
+	}
+}
+
+// autoCancelReservation cancels a reservation that was not borrowed within
+// ReservationHold. hold identifies the reservation, so an expiry that fires
+// late cannot cancel a newer reservation by the same member.
+func (l *Library) autoCancelReservation(bookID int, memberID int, hold *pendingHold) {
+	l.lock()
 	defer l.mu.Unlock()
 
+	if l.holds[bookID] != hold {
+		return
+	}
+	delete(l.holds, bookID)
+
 	book, exists := l.Books[bookID]
 	if !exists {
 		return
 	}
 	// If still reserved by the same member, cancel the reservation.
-	if book.Status == "Reserved" && book.ReservedBy == memberID {
-		book.Status = "Available"
+	if book.Status == models.StatusReserved && book.ReservedBy == memberID {
+		book.Status = models.StatusAvailable
 		book.ReservedBy = 0
 		l.Books[bookID] = book
-		fmt.Printf("Auto-cancellation: Reservation for book %d by member %d has timed out.\n", bookID, memberID)
+		l.metrics.reservations.Inc(ReservationExpired)
+		l.publish(events.ReservationExpired, bookID, memberID)
+		l.notify(memberID, notifications.ReservationExpired, book,
+			fmt.Sprintf("Your hold on %q has expired.", book.Title))
+		// A hold at a pickup branch sends the book back home.
+		if book.Branch != book.HomeBranch {
+			l.startTransfer(book, book.HomeBranch)
+		}
+	}
+}
+
+// SetNotifier sets where member notifications are delivered. A nil notifier
+// disables notifications.
+func (l *Library) SetNotifier(notifier notifications.Notifier) {
+	l.lock()
+	defer l.mu.Unlock()
+	l.notifier = notifier
+}
+
+// SetPreferences replaces the members' notification preferences, such as
+// with ones loaded by notifications.OpenPreferences.
+func (l *Library) SetPreferences(prefs *notifications.Preferences) {
+	l.lock()
+	defer l.mu.Unlock()
+	l.prefs = prefs
+}
+
+// SetNotificationPreference enables or disables a kind of notification for
+// a member. It returns an error if the preferences could not be saved.
+func (l *Library) SetNotificationPreference(memberID int, kind notifications.Kind, enabled bool) error {
+	return l.preferences().Set(memberID, kind, enabled)
+}
+
+// NotificationPreferences reports which kinds of notification a member receives.
+func (l *Library) NotificationPreferences(memberID int) map[notifications.Kind]bool {
+	current := l.preferences()
+	prefs := make(map[notifications.Kind]bool, len(notifications.Kinds))
+	for _, kind := range notifications.Kinds {
+		prefs[kind] = current.Allows(memberID, kind)
+	}
+	return prefs
+}
+
+// preferences returns the current preferences without holding l.mu while
+// they are read or saved.
+func (l *Library) preferences() *notifications.Preferences {
+	l.lock()
+	defer l.mu.Unlock()
+	return l.prefs
+}
+
+// CheckDueDates reminds members whose loans are due soon or overdue as of now.
+// Each loan gets at most one due-soon and one overdue notification.
+func (l *Library) CheckDueDates(now time.Time) {
+	l.lock()
+	defer l.mu.Unlock()
+
+	for _, member := range l.Members {
+		for _, loan := range member.Loans {
+			book, exists := l.Books[loan.BookID]
+			if !exists || book.Status != models.StatusBorrowed {
+				continue
+			}
+			switch {
+			case now.After(loan.DueDate):
+				if l.reminders[book.ID] != notifications.Overdue {
+					l.reminders[book.ID] = notifications.Overdue
+					l.notify(member.ID, notifications.Overdue, book,
+						fmt.Sprintf("%q was due on %s. Please return it as soon as possible.", book.Title, loan.DueDate.Format("2006-01-02")))
+				}
+			case now.Add(DueSoonWindow).After(loan.DueDate):
+				if _, sent := l.reminders[book.ID]; !sent {
+					l.reminders[book.ID] = notifications.DueSoon
+					l.notify(member.ID, notifications.DueSoon, book,
+						fmt.Sprintf("%q is due on %s.", book.Title, loan.DueDate.Format("2006-01-02")))
+				}
+			}
+		}
+	}
+}
+
+// StartDueDateMonitor checks due dates every interval, as measured by the
+// library's clock, until the returned stop function is called.
+func (l *Library) StartDueDateMonitor(interval time.Duration) (stop func()) {
+	var (
+		mu      sync.Mutex
+		timer   clock.Timer
+		stopped bool
+		tick    func()
+	)
+	tick = func() {
+		l.CheckDueDates(l.clock.Now())
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			timer = l.clock.AfterFunc(interval, tick)
+		}
+	}
+	mu.Lock()
+	timer = l.clock.AfterFunc(interval, tick)
+	mu.Unlock()
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			stopped = true
+			timer.Stop()
+		}
+	}
+}
+
+// notify sends a notification if a notifier is set and the member has not
+// opted out of this kind. Callers hold l.mu.
+func (l *Library) notify(memberID int, kind notifications.Kind, book models.Book, text string) {
+	if l.notifier == nil || !l.prefs.Allows(memberID, kind) {
+		return
 	}
+	err := l.notifier.Notify(notifications.Message{
+		MemberID: memberID,
+		Kind:     kind,
+		BookID:   book.ID,
+		Text:     text,
+		Time:     l.clock.Now(),
+	})
+	if err != nil {
+		log.Printf("notify member %d (%s): %v", memberID, kind, err)
+	}
+}
+
+// Subscribe returns a channel of library events and a function that cancels
+// the subscription. Events are delivered in the order they happened.
+func (l *Library) Subscribe() (<-chan events.Event, func()) {
+	return l.bus.Subscribe()
+}
+
+// Close stops the event stream; subscribers receive any pending events and
+// then see their channels closed.
+func (l *Library) Close() {
+	l.bus.Close()
 }
 
-// AddMember adds a new member to the library.
-func (l *Library) AddMember(member models.Member) {
-	l.mu.Lock()
+// publish emits an event; callers hold l.mu so events follow mutation order.
+func (l *Library) publish(t events.Type, bookID int, memberID int) {
+	l.bus.Publish(events.Event{
+		Type:     t,
+		BookID:   bookID,
+		MemberID: memberID,
+		Time:     l.clock.Now(),
+	})
+}
+
+// AddMember adds a new member to the library. Adding a member whose ID is
+// already in use returns ErrMemberExists and leaves the existing member, and
+// their loans, unchanged.
+func (l *Library) AddMember(member models.Member) error {
+	l.lock()
 	defer l.mu.Unlock()
+	if _, exists := l.Members[member.ID]; exists {
+		return ErrMemberExists
+	}
 	l.Members[member.ID] = member
+	return nil
+}
+
+// UpdateMember changes a member's name.
+func (l *Library) UpdateMember(memberID int, name string) error {
+	l.lock()
+	defer l.mu.Unlock()
+
+	member, exists := l.Members[memberID]
+	if !exists {
+		return ErrMemberNotFound
+	}
+	member.Name = name
+	l.Members[memberID] = member
+	return nil
+}
+
+// SetMemberSuspended suspends or reinstates a member. Suspended members keep
+// their current loans and can return books but cannot borrow or reserve.
+func (l *Library) SetMemberSuspended(memberID int, suspended bool) error {
+	l.lock()
+	defer l.mu.Unlock()
+
+	member, exists := l.Members[memberID]
+	if !exists {
+		return ErrMemberNotFound
+	}
+	member.Suspended = suspended
+	l.Members[memberID] = member
+	return nil
+}
+
+// RemoveMember deletes a member who has no borrowed books. Any book the
+// member has reserved becomes available again.
+func (l *Library) RemoveMember(memberID int) error {
+	l.lock()
+	defer l.mu.Unlock()
+
+	member, exists := l.Members[memberID]
+	if !exists {
+		return ErrMemberNotFound
+	}
+	if len(member.Loans) > 0 {
+		return ErrMemberHasLoans
+	}
+
+	for id, book := range l.Books {
+		if book.ReservedBy != memberID {
+			continue
+		}
+		switch book.Status {
+		case models.StatusReserved:
+			l.cancelHold(id)
+			book.Status = models.StatusAvailable
+			book.ReservedBy = 0
+			l.Books[id] = book
+			// A hold at a pickup branch sends the book back home.
+			if book.Branch != book.HomeBranch {
+				l.startTransfer(book, book.HomeBranch)
+			}
+		case models.StatusInTransit:
+			// The book is on its way to the member's pickup branch; it
+			// becomes Available there instead of being held for nobody.
+			book.ReservedBy = 0
+			l.Books[id] = book
+		default:
+			continue
+		}
+		l.metrics.reservations.Inc(ReservationCancelled)
+		l.publish(events.ReservationCancelled, id, memberID)
+	}
+	delete(l.Members, memberID)
+	return nil
+}
+
+// GetBook returns the book with the given ID.
+func (l *Library) GetBook(bookID int) (models.Book, error) {
+	l.lock()
+	defer l.mu.Unlock()
+
+	book, exists := l.Books[bookID]
+	if !exists {
+		return models.Book{}, ErrBookNotFound
+	}
+	return book, nil
+}
+
+// ListBooks returns every book in the library ordered by ID.
+func (l *Library) ListBooks() []models.Book {
+	l.lock()
+	defer l.mu.Unlock()
+
+	books := make([]models.Book, 0, len(l.Books))
+	for _, book := range l.Books {
+		books = append(books, book)
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	return books
+}
+
+// GetMember returns the member with the given ID.
+func (l *Library) GetMember(memberID int) (models.Member, error) {
+	l.lock()
+	defer l.mu.Unlock()
+
+	member, exists := l.Members[memberID]
+	if !exists {
+		return models.Member{}, ErrMemberNotFound
+	}
+	return member, nil
+}
+
+// ListMembers returns every member ordered by ID.
+func (l *Library) ListMembers() []models.Member {
+	l.lock()
+	defer l.mu.Unlock()
+
+	members := make([]models.Member, 0, len(l.Members))
+	for _, member := range l.Members {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+	return members
 }
 
 // ListAllBooks prints all books (for debugging or full listing).
 func (l *Library) ListAllBooks() {
-	l.mu.Lock()
+	l.lock()
 	defer l.mu.Unlock()
 	fmt.Println("All Books in Library:")
 	for _, book := range l.Books {
-		fmt.Printf("ID: %d, Title: %s, Author: %s, Status: %s, ReservedBy: %d\n", book.ID, book.Title, book.Author, book.Status, book.ReservedBy)
+		fmt.Printf("ID: %d, Title: %s, Author: %s, Status: %s, ReservedBy: %d, Branch: %d\n", book.ID, book.Title, book.Author, book.Status, book.ReservedBy, book.Branch)
+	}
+}
+
+// SearchBooks returns the books whose title or author match the query,
+// most relevant first. Each query term may match exactly, as a prefix,
+// or with a small number of typos.
+func (l *Library) SearchBooks(query string) []models.Book {
+	l.lock()
+	defer l.mu.Unlock()
+
+	results := l.index.Search(query)
+	books := make([]models.Book, 0, len(results))
+	for _, r := range results {
+		if book, exists := l.Books[r.ID]; exists {
+			books = append(books, book)
+		}
 	}
+	return books
 }
@@ -0,0 +1,674 @@
+package services
+
+import (
+	"errors"
+	"library_management/clock"
+	"library_management/events"
+	"library_management/models"
+	"library_management/notifications"
+	"sync"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// recorder is a Notifier that keeps every message.
+type recorder struct {
+	mu       sync.Mutex
+	messages []notifications.Message
+}
+
+func (r *recorder) Notify(msg notifications.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *recorder) kinds() []notifications.Kind {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kinds := make([]notifications.Kind, len(r.messages))
+	for i, msg := range r.messages {
+		kinds[i] = msg.Kind
+	}
+	return kinds
+}
+
+func newTestLibrary(t *testing.T) (*Library, *clock.Fake) {
+	t.Helper()
+	fake := clock.NewFake(start)
+	library := NewLibraryWithClock(fake)
+	t.Cleanup(library.Close)
+	library.AddMember(models.Member{ID: 1, Name: "Alice"})
+	library.AddMember(models.Member{ID: 2, Name: "Bob"})
+	library.AddBook(models.Book{ID: 101, Title: "Go Programming", Author: "John Doe"})
+	return library, fake
+}
+
+func mustStatus(t *testing.T, library *Library, bookID int, want models.BookStatus) models.Book {
+	t.Helper()
+	book, err := library.GetBook(bookID)
+	if err != nil {
+		t.Fatalf("GetBook(%d): %v", bookID, err)
+	}
+	if book.Status != want {
+		t.Fatalf("book %d status = %q, want %q", bookID, book.Status, want)
+	}
+	return book
+}
+
+func TestReservationExpiresAfterHold(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	if err := library.ReserveBook(101, 1); err != nil {
+		t.Fatalf("ReserveBook: %v", err)
+	}
+
+	fake.Advance(ReservationHold - time.Millisecond)
+	if book := mustStatus(t, library, 101, models.StatusReserved); book.ReservedBy != 1 {
+		t.Fatalf("ReservedBy = %d, want 1", book.ReservedBy)
+	}
+
+	fake.Advance(time.Millisecond)
+	if book := mustStatus(t, library, 101, models.StatusAvailable); book.ReservedBy != 0 {
+		t.Fatalf("ReservedBy = %d after expiry, want 0", book.ReservedBy)
+	}
+	if err := library.BorrowBook(101, 2); err != nil {
+		t.Fatalf("another member could not borrow after expiry: %v", err)
+	}
+}
+
+func TestAddBookRejectsDuplicateID(t *testing.T) {
+	library, _ := newTestLibrary(t)
+	if err := library.BorrowBook(101, 1); err != nil {
+		t.Fatalf("BorrowBook: %v", err)
+	}
+	if err := library.AddBook(models.Book{ID: 101, Title: "Replacement"}); err != ErrBookExists {
+		t.Fatalf("AddBook with a used ID = %v, want %v", err, ErrBookExists)
+	}
+	if book := mustStatus(t, library, 101, models.StatusBorrowed); book.Title != "Go Programming" {
+		t.Fatalf("title = %q after rejected AddBook, want the original", book.Title)
+	}
+}
+
+func TestAddMemberRejectsDuplicateID(t *testing.T) {
+	library, _ := newTestLibrary(t)
+	if err := library.BorrowBook(101, 1); err != nil {
+		t.Fatalf("BorrowBook: %v", err)
+	}
+	if err := library.AddMember(models.Member{ID: 1, Name: "Replacement"}); err != ErrMemberExists {
+		t.Fatalf("AddMember with a used ID = %v, want %v", err, ErrMemberExists)
+	}
+	member, _ := library.GetMember(1)
+	if member.Name != "Alice" || len(member.Loans) != 1 {
+		t.Fatalf("member = %+v after rejected AddMember, want Alice with her loan", member)
+	}
+	if issues := library.CheckIntegrity(false); len(issues) != 0 {
+		t.Fatalf("integrity issues: %v", issues)
+	}
+	if err := library.ReturnBook(101, 1); err != nil {
+		t.Fatalf("ReturnBook: %v", err)
+	}
+}
+
+func TestAddBranchRejectsDuplicateID(t *testing.T) {
+	library, _ := newTestLibrary(t)
+	if err := library.AddBranch(models.Branch{ID: DefaultBranch, Name: "Replacement"}); err != ErrBranchExists {
+		t.Fatalf("AddBranch with a used ID = %v, want %v", err, ErrBranchExists)
+	}
+	if branches := library.ListBranches(); len(branches) != 1 || branches[0].Name == "Replacement" {
+		t.Fatalf("branches = %+v after rejected AddBranch, want the original", branches)
+	}
+}
+
+func TestMemberAndBookManagement(t *testing.T) {
+	borrow := func(l *Library) error { return l.BorrowBook(101, 1) }
+	suspend := func(l *Library) error { return l.SetMemberSuspended(1, true) }
+	tests := []struct {
+		name    string
+		prepare []func(l *Library) error
+		op      func(l *Library) error
+		want    error
+		check   func(t *testing.T, l *Library)
+	}{
+		{
+			name:    "remove borrowed book",
+			prepare: []func(*Library) error{borrow},
+			op:      func(l *Library) error { return l.RemoveBook(101) },
+			want:    ErrBookOnLoan,
+			check:   func(t *testing.T, l *Library) { mustStatus(t, l, 101, models.StatusBorrowed) },
+		},
+		{
+			name:    "remove returned book",
+			prepare: []func(*Library) error{borrow, func(l *Library) error { return l.ReturnBook(101, 1) }},
+			op:      func(l *Library) error { return l.RemoveBook(101) },
+			check: func(t *testing.T, l *Library) {
+				if _, err := l.GetBook(101); err != ErrBookNotFound {
+					t.Fatalf("GetBook after removal = %v, want %v", err, ErrBookNotFound)
+				}
+			},
+		},
+		{
+			name: "remove unknown book",
+			op:   func(l *Library) error { return l.RemoveBook(999) },
+			want: ErrBookNotFound,
+		},
+		{
+			name:    "remove member with loans",
+			prepare: []func(*Library) error{borrow},
+			op:      func(l *Library) error { return l.RemoveMember(1) },
+			want:    ErrMemberHasLoans,
+			check: func(t *testing.T, l *Library) {
+				if loans := l.ListLoans(1); len(loans) != 1 {
+					t.Fatalf("member 1 has %d loans after the rejected removal, want 1", len(loans))
+				}
+			},
+		},
+		{
+			name:    "remove member after returning",
+			prepare: []func(*Library) error{borrow, func(l *Library) error { return l.ReturnBook(101, 1) }},
+			op:      func(l *Library) error { return l.RemoveMember(1) },
+			check: func(t *testing.T, l *Library) {
+				if _, err := l.GetMember(1); err != ErrMemberNotFound {
+					t.Fatalf("GetMember after removal = %v, want %v", err, ErrMemberNotFound)
+				}
+			},
+		},
+		{
+			name: "remove unknown member",
+			op:   func(l *Library) error { return l.RemoveMember(99) },
+			want: ErrMemberNotFound,
+		},
+		{
+			name:    "rename member",
+			prepare: []func(*Library) error{borrow},
+			op:      func(l *Library) error { return l.UpdateMember(1, "Alicia") },
+			check: func(t *testing.T, l *Library) {
+				member, _ := l.GetMember(1)
+				if member.Name != "Alicia" || len(member.Loans) != 1 {
+					t.Fatalf("member = %+v, want Alicia with her loan", member)
+				}
+			},
+		},
+		{
+			name: "rename unknown member",
+			op:   func(l *Library) error { return l.UpdateMember(99, "Nobody") },
+			want: ErrMemberNotFound,
+		},
+		{
+			name: "suspend unknown member",
+			op:   func(l *Library) error { return l.SetMemberSuspended(99, true) },
+			want: ErrMemberNotFound,
+		},
+		{
+			name:    "borrow while suspended",
+			prepare: []func(*Library) error{suspend},
+			op:      borrow,
+			want:    ErrMemberSuspended,
+			check:   func(t *testing.T, l *Library) { mustStatus(t, l, 101, models.StatusAvailable) },
+		},
+		{
+			name:    "reserve while suspended",
+			prepare: []func(*Library) error{suspend},
+			op:      func(l *Library) error { return l.ReserveBook(101, 1) },
+			want:    ErrMemberSuspended,
+			check:   func(t *testing.T, l *Library) { mustStatus(t, l, 101, models.StatusAvailable) },
+		},
+		{
+			name:    "return while suspended",
+			prepare: []func(*Library) error{borrow, suspend},
+			op:      func(l *Library) error { return l.ReturnBook(101, 1) },
+			check:   func(t *testing.T, l *Library) { mustStatus(t, l, 101, models.StatusAvailable) },
+		},
+		{
+			name:    "borrow after reinstating",
+			prepare: []func(*Library) error{suspend, func(l *Library) error { return l.SetMemberSuspended(1, false) }},
+			op:      borrow,
+			check:   func(t *testing.T, l *Library) { mustStatus(t, l, 101, models.StatusBorrowed) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library, _ := newTestLibrary(t)
+			for i, prepare := range tt.prepare {
+				if err := prepare(library); err != nil {
+					t.Fatalf("prepare step %d: %v", i, err)
+				}
+			}
+			if err := tt.op(library); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, library)
+			}
+			if issues := library.CheckIntegrity(false); len(issues) != 0 {
+				t.Fatalf("integrity issues: %v", issues)
+			}
+		})
+	}
+}
+
+func TestReservedBookOnlyBorrowedByReserver(t *testing.T) {
+	library, _ := newTestLibrary(t)
+	if err := library.ReserveBook(101, 1); err != nil {
+		t.Fatalf("ReserveBook: %v", err)
+	}
+	if err := library.BorrowBook(101, 2); err != ErrBookReserved {
+		t.Fatalf("BorrowBook by other member = %v, want %v", err, ErrBookReserved)
+	}
+	if err := library.BorrowBook(101, 1); err != nil {
+		t.Fatalf("BorrowBook by reserver: %v", err)
+	}
+}
+
+func TestReserveUnavailableBookSaysWhy(t *testing.T) {
+	library, _ := newTestLibrary(t)
+	if err := library.ReserveBook(101, 1); err != nil {
+		t.Fatalf("ReserveBook: %v", err)
+	}
+	err := library.ReserveBook(101, 2)
+	if !errors.Is(err, ErrBookNotAvailable) || !errors.Is(err, ErrBookReserved) {
+		t.Fatalf("ReserveBook of a reserved book = %v, want %v and %v", err, ErrBookNotAvailable, ErrBookReserved)
+	}
+	if err := library.BorrowBook(101, 1); err != nil {
+		t.Fatalf("BorrowBook: %v", err)
+	}
+	err = library.ReserveBook(101, 2)
+	if !errors.Is(err, ErrBookNotAvailable) || !errors.Is(err, ErrBookBorrowed) {
+		t.Fatalf("ReserveBook of a borrowed book = %v, want %v and %v", err, ErrBookNotAvailable, ErrBookBorrowed)
+	}
+}
+
+func TestBorrowBeforeExpiryCancelsHold(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	if err := library.ReserveBook(101, 1); err != nil {
+		t.Fatalf("ReserveBook: %v", err)
+	}
+	fake.Advance(ReservationHold / 2)
+	if err := library.BorrowBook(101, 1); err != nil {
+		t.Fatalf("BorrowBook: %v", err)
+	}
+	if n := fake.Pending(); n != 0 {
+		t.Fatalf("%d timers pending after borrow, want 0", n)
+	}
+
+	fake.Advance(ReservationHold)
+	mustStatus(t, library, 101, models.StatusBorrowed)
+}
+
+func TestStaleExpiryKeepsNewerReservation(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	if err := library.ReserveBook(101, 1); err != nil {
+		t.Fatalf("ReserveBook: %v", err)
+	}
+	if err := library.UpdateBookStatus(101, models.StatusDamaged); err != nil {
+		t.Fatalf("UpdateBookStatus(damaged): %v", err)
+	}
+	if err := library.UpdateBookStatus(101, models.StatusInRepair); err != nil {
+		t.Fatalf("UpdateBookStatus(in repair): %v", err)
+	}
+	if err := library.UpdateBookStatus(101, models.StatusAvailable); err != nil {
+		t.Fatalf("UpdateBookStatus(available): %v", err)
+	}
+
+	// Reserve again partway through the first hold; the new hold must last
+	// its full period.
+	fake.Advance(3 * time.Second)
+	if err := library.ReserveBook(101, 1); err != nil {
+		t.Fatalf("second ReserveBook: %v", err)
+	}
+	fake.Advance(ReservationHold - time.Millisecond)
+	mustStatus(t, library, 101, models.StatusReserved)
+	fake.Advance(time.Millisecond)
+	mustStatus(t, library, 101, models.StatusAvailable)
+}
+
+// expiringClock fires every timer at once from its own goroutine, like a
+// real timer that expires before AfterFunc has returned.
+type expiringClock struct{}
+
+func (expiringClock) Now() time.Time { return start }
+
+func (expiringClock) AfterFunc(_ time.Duration, f func()) clock.Timer {
+	return time.AfterFunc(0, f)
+}
+
+func TestHoldExpiringImmediatelyIsCancelled(t *testing.T) {
+	library := NewLibraryWithClock(expiringClock{})
+	t.Cleanup(library.Close)
+	library.AddMember(models.Member{ID: 1, Name: "Alice"})
+	library.AddBook(models.Book{ID: 101, Title: "Go Programming"})
+	stream, cancel := library.Subscribe()
+	defer cancel()
+
+	// The expiry runs as soon as the reservation releases the library lock
+	// and must recognise the hold it belongs to.
+	if err := library.ReserveBook(101, 1); err != nil {
+		t.Fatalf("ReserveBook: %v", err)
+	}
+	for _, typ := range []events.Type{events.BookReserved, events.ReservationExpired} {
+		select {
+		case e := <-stream:
+			if e.Type != typ {
+				t.Fatalf("event %s, want %s", e.Type, typ)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s event", typ)
+		}
+	}
+	mustStatus(t, library, 101, models.StatusAvailable)
+}
+
+func TestReservationExpiryEventsAndNotifications(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	notifier := &recorder{}
+	library.SetNotifier(notifier)
+	stream, cancel := library.Subscribe()
+	defer cancel()
+
+	if err := library.ReserveBook(101, 1); err != nil {
+		t.Fatalf("ReserveBook: %v", err)
+	}
+	fake.Advance(ReservationHold)
+
+	want := []struct {
+		typ  events.Type
+		time time.Time
+	}{
+		{events.BookReserved, start},
+		{events.ReservationExpired, start.Add(ReservationHold)},
+	}
+	for _, w := range want {
+		select {
+		case e := <-stream:
+			if e.Type != w.typ || e.BookID != 101 || e.MemberID != 1 || !e.Time.Equal(w.time) {
+				t.Fatalf("event = %+v, want %s for book 101 by member 1 at %s", e, w.typ, w.time)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s event", w.typ)
+		}
+	}
+
+	kinds := notifier.kinds()
+	if len(kinds) != 2 || kinds[0] != notifications.ReservationReady || kinds[1] != notifications.ReservationExpired {
+		t.Fatalf("notifications = %v, want [%s %s]", kinds, notifications.ReservationReady, notifications.ReservationExpired)
+	}
+}
+
+func TestRemoveMemberCancelsReservation(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	stream, cancel := library.Subscribe()
+	defer cancel()
+
+	if err := library.ReserveBook(101, 1); err != nil {
+		t.Fatalf("ReserveBook: %v", err)
+	}
+	if err := library.RemoveMember(1); err != nil {
+		t.Fatalf("RemoveMember: %v", err)
+	}
+	if n := fake.Pending(); n != 0 {
+		t.Fatalf("%d timers pending after removing the member, want 0", n)
+	}
+	mustStatus(t, library, 101, models.StatusAvailable)
+
+	for _, typ := range []events.Type{events.BookReserved, events.ReservationCancelled} {
+		select {
+		case e := <-stream:
+			if e.Type != typ || e.BookID != 101 || e.MemberID != 1 {
+				t.Fatalf("event = %+v, want %s for book 101 by member 1", e, typ)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s event", typ)
+		}
+	}
+}
+
+func TestLoadHistoryFeedsRecommendations(t *testing.T) {
+	library, _ := newTestLibrary(t)
+	library.AddBook(models.Book{ID: 102, Title: "Concurrency in Go", Author: "Jane Roe"})
+	past := []events.Event{
+		{Type: events.BookBorrowed, BookID: 101, MemberID: 2},
+		{Type: events.BookReturned, BookID: 101, MemberID: 2},
+		{Type: events.BookBorrowed, BookID: 102, MemberID: 2},
+		{Type: events.BookReserved, BookID: 102, MemberID: 1},
+	}
+	if n := library.LoadHistory(past); n != 2 {
+		t.Fatalf("LoadHistory loaded %d borrows, want 2", n)
+	}
+
+	recs, err := library.AlsoBorrowed(101, 0)
+	if err != nil {
+		t.Fatalf("AlsoBorrowed: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Book.ID != 102 || recs[0].Score != 1 {
+		t.Fatalf("AlsoBorrowed(101) = %+v, want book 102 with score 1", recs)
+	}
+}
+
+func TestLoanDueDateUsesClock(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	fake.Advance(time.Hour)
+	if err := library.BorrowBook(101, 1); err != nil {
+		t.Fatalf("BorrowBook: %v", err)
+	}
+	loans := library.ListLoans(1)
+	if len(loans) != 1 {
+		t.Fatalf("got %d loans, want 1", len(loans))
+	}
+	borrowed := start.Add(time.Hour)
+	if !loans[0].BorrowedAt.Equal(borrowed) || !loans[0].DueDate.Equal(borrowed.Add(LoanPeriod)) {
+		t.Fatalf("loan = %+v, want borrowed at %s and due %s", loans[0], borrowed, borrowed.Add(LoanPeriod))
+	}
+}
+
+func TestOpenLoans(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	library.AddBook(models.Book{ID: 102, Title: "Introducing Go"})
+	library.AddBook(models.Book{ID: 103, Title: "Clean Code"})
+	for _, loan := range []struct{ bookID, memberID int }{{101, 1}, {102, 2}} {
+		if err := library.BorrowBook(loan.bookID, loan.memberID); err != nil {
+			t.Fatalf("BorrowBook(%d, %d): %v", loan.bookID, loan.memberID, err)
+		}
+	}
+	if err := library.ReturnBook(102, 2); err != nil {
+		t.Fatalf("ReturnBook: %v", err)
+	}
+	fake.Advance(LoanPeriod - time.Hour)
+	if err := library.BorrowBook(103, 2); err != nil {
+		t.Fatalf("BorrowBook: %v", err)
+	}
+	fake.Advance(2 * time.Hour)
+
+	loans := library.OpenLoans()
+	if len(loans) != 2 || loans[0].BookID != 101 || loans[1].BookID != 103 {
+		t.Fatalf("open loans = %+v, want books 101 and 103 by due date", loans)
+	}
+	if now := library.Now(); !now.After(loans[0].DueDate) || now.After(loans[1].DueDate) {
+		t.Errorf("at %s, want book 101 (due %s) overdue and book 103 (due %s) not", now, loans[0].DueDate, loans[1].DueDate)
+	}
+}
+
+func TestDueDateMonitorSendsEachReminderOnce(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	notifier := &recorder{}
+	library.SetNotifier(notifier)
+	if err := library.BorrowBook(101, 1); err != nil {
+		t.Fatalf("BorrowBook: %v", err)
+	}
+	stop := library.StartDueDateMonitor(time.Hour)
+	defer stop()
+
+	fake.Advance(LoanPeriod - DueSoonWindow - time.Hour)
+	if kinds := notifier.kinds(); len(kinds) != 0 {
+		t.Fatalf("notifications before the due-soon window = %v, want none", kinds)
+	}
+	fake.Advance(2 * time.Hour)
+	fake.Advance(DueSoonWindow)
+	kinds := notifier.kinds()
+	if len(kinds) != 2 || kinds[0] != notifications.DueSoon || kinds[1] != notifications.Overdue {
+		t.Fatalf("notifications = %v, want [%s %s]", kinds, notifications.DueSoon, notifications.Overdue)
+	}
+
+	stop()
+	fake.Advance(24 * time.Hour)
+	if n := fake.Pending(); n != 0 {
+		t.Fatalf("%d timers pending after stop, want 0", n)
+	}
+}
+
+func TestReservationMetrics(t *testing.T) {
+	library, fake := newTestLibrary(t)
+	library.AddBook(models.Book{ID: 102, Title: "Concurrency in Go", Author: "Jane Roe"})
+
+	library.ReserveBook(101, 1)
+	library.ReserveBook(101, 2) // Rejected: already reserved
+	library.BorrowBook(101, 1)  // Fulfilled
+	library.ReserveBook(102, 2)
+	fake.Advance(ReservationHold) // Expired
+	library.ReturnBook(101, 1)
+
+	m := library.metrics
+	for outcome, want := range map[string]uint64{
+		ReservationPlaced: 2, ReservationRejected: 1, ReservationFulfilled: 1, ReservationExpired: 1,
+	} {
+		if got := m.reservations.Value(outcome); got != want {
+			t.Errorf("reservations{%s} = %d, want %d", outcome, got, want)
+		}
+	}
+	if m.borrows.Value() != 1 || m.returns.Value() != 1 {
+		t.Errorf("borrows = %d, returns = %d, want 1 and 1", m.borrows.Value(), m.returns.Value())
+	}
+	if m.lockWait.Count() == 0 {
+		t.Error("lock wait was not observed")
+	}
+}
+
+func TestUpdateBookStatusTransitions(t *testing.T) {
+	// setups put book 101 into each status a librarian may find it in.
+	setups := []struct {
+		name    string
+		from    models.BookStatus
+		prepare func(l *Library) error
+	}{
+		{"available", models.StatusAvailable, func(l *Library) error { return nil }},
+		{"reserved", models.StatusReserved, func(l *Library) error { return l.ReserveBook(101, 1) }},
+		{"borrowed", models.StatusBorrowed, func(l *Library) error { return l.BorrowBook(101, 1) }},
+		{"lost", models.StatusLost, func(l *Library) error { return l.UpdateBookStatus(101, models.StatusLost) }},
+		{"damaged", models.StatusDamaged, func(l *Library) error { return l.UpdateBookStatus(101, models.StatusDamaged) }},
+		{"in repair", models.StatusInRepair, func(l *Library) error {
+			if err := l.UpdateBookStatus(101, models.StatusDamaged); err != nil {
+				return err
+			}
+			return l.UpdateBookStatus(101, models.StatusInRepair)
+		}},
+		{"withdrawn", models.StatusWithdrawn, func(l *Library) error { return l.UpdateBookStatus(101, models.StatusWithdrawn) }},
+		{"in transit", models.StatusInTransit, func(l *Library) error { return l.TransferBook(101, eastBranch) }},
+		{"in transit for pickup", models.StatusInTransit, func(l *Library) error { return l.ReserveBookAt(101, 1, eastBranch) }},
+	}
+	// allowed lists the statuses UpdateBookStatus accepts from each status.
+	// Reserved, Borrowed and In Transit are only reached through their own
+	// operations, and a borrowed book must be returned to become Available.
+	allowed := map[models.BookStatus][]models.BookStatus{
+		models.StatusAvailable: {models.StatusLost, models.StatusDamaged, models.StatusWithdrawn},
+		models.StatusReserved:  {models.StatusAvailable, models.StatusLost, models.StatusDamaged},
+		models.StatusBorrowed:  {models.StatusLost, models.StatusDamaged},
+		models.StatusLost:      {models.StatusAvailable, models.StatusWithdrawn},
+		models.StatusDamaged:   {models.StatusAvailable, models.StatusInRepair, models.StatusWithdrawn},
+		models.StatusInRepair:  {models.StatusAvailable, models.StatusWithdrawn},
+		models.StatusInTransit: {models.StatusAvailable, models.StatusLost, models.StatusDamaged},
+	}
+
+	for _, setup := range setups {
+		for _, to := range models.Statuses {
+			ok := false
+			for _, status := range allowed[setup.from] {
+				ok = ok || status == to
+			}
+			t.Run(setup.name+" to "+string(to), func(t *testing.T) {
+				library, _ := newBranchLibrary(t)
+				if err := setup.prepare(library); err != nil {
+					t.Fatalf("prepare: %v", err)
+				}
+				mustStatus(t, library, 101, setup.from)
+
+				err := library.UpdateBookStatus(101, to)
+				if ok {
+					if err != nil {
+						t.Fatalf("UpdateBookStatus = %v, want success", err)
+					}
+					mustStatus(t, library, 101, to)
+					return
+				}
+				if !errors.Is(err, models.ErrInvalidTransition) {
+					t.Fatalf("UpdateBookStatus = %v, want %v", err, models.ErrInvalidTransition)
+				}
+				var transitionErr *models.TransitionError
+				if !errors.As(err, &transitionErr) || transitionErr.From != setup.from || transitionErr.To != to {
+					t.Fatalf("UpdateBookStatus = %#v, want a TransitionError from %s to %s", err, setup.from, to)
+				}
+				mustStatus(t, library, 101, setup.from)
+			})
+		}
+	}
+}
+
+func TestUpdateBookStatusCancelsReservation(t *testing.T) {
+	tests := []struct {
+		name    string
+		reserve func(l *Library) error
+		status  models.BookStatus
+	}{
+		{"held", func(l *Library) error { return l.ReserveBook(101, 1) }, models.StatusLost},
+		{"held, made available", func(l *Library) error { return l.ReserveBook(101, 1) }, models.StatusAvailable},
+		{"on the way to pickup", func(l *Library) error { return l.ReserveBookAt(101, 1, eastBranch) }, models.StatusDamaged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library, fake := newTestLibrary(t)
+			library.AddBranch(models.Branch{ID: eastBranch, Name: "East"})
+			notifier := &recorder{}
+			library.SetNotifier(notifier)
+			if err := tt.reserve(library); err != nil {
+				t.Fatalf("reserve: %v", err)
+			}
+			stream, cancel := library.Subscribe()
+			defer cancel()
+
+			if err := library.UpdateBookStatus(101, tt.status); err != nil {
+				t.Fatalf("UpdateBookStatus: %v", err)
+			}
+			if book := mustStatus(t, library, 101, tt.status); book.ReservedBy != 0 {
+				t.Fatalf("ReservedBy = %d, want 0", book.ReservedBy)
+			}
+			if n := fake.Pending(); n != 0 {
+				t.Fatalf("%d timers pending after the status change, want 0", n)
+			}
+			for _, typ := range []events.Type{events.ReservationCancelled, events.BookStatusChanged} {
+				select {
+				case e := <-stream:
+					if e.Type != typ || e.BookID != 101 {
+						t.Fatalf("event = %+v, want %s for book 101", e, typ)
+					}
+					if typ == events.ReservationCancelled && e.MemberID != 1 {
+						t.Fatalf("reservation cancelled for member %d, want 1", e.MemberID)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("no %s event", typ)
+				}
+			}
+			if kinds := notifier.kinds(); len(kinds) == 0 || kinds[len(kinds)-1] != notifications.ReservationCancelled {
+				t.Fatalf("notifications = %v, want the last to be %s", kinds, notifications.ReservationCancelled)
+			}
+			if got := library.metrics.reservations.Value(ReservationCancelled); got != 1 {
+				t.Fatalf("reservations{%s} = %d, want 1", ReservationCancelled, got)
+			}
+
+			// The expiry of the cancelled hold never fires.
+			fake.Advance(2 * ReservationHold)
+			if got := library.metrics.reservations.Value(ReservationExpired); got != 0 {
+				t.Fatalf("reservations{%s} = %d after the hold, want 0", ReservationExpired, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"library_management/metrics"
+	"time"
+)
+
+// Reservation outcomes counted by library_reservations_total.
+const (
+	ReservationPlaced    = "placed"    // A member reserved a book
+	ReservationRejected  = "rejected"  // ReserveBook returned an error
+	ReservationFulfilled = "fulfilled" // The reserving member borrowed the book
+	ReservationExpired   = "expired"   // The hold ran out
+	ReservationCancelled = "cancelled" // The reserving member was removed or the book's status was changed
+)
+
+// libraryMetrics counts library operations. The counters are always kept;
+// RegisterMetrics exposes them.
+type libraryMetrics struct {
+	borrows      *metrics.Counter
+	returns      *metrics.Counter
+	reservations *metrics.CounterVec
+	lockWait     *metrics.Histogram
+}
+
+func newLibraryMetrics() *libraryMetrics {
+	return &libraryMetrics{
+		borrows: metrics.NewCounter("library_borrows_total", "Books lent to members."),
+		returns: metrics.NewCounter("library_returns_total", "Books returned by members."),
+		reservations: metrics.NewCounterVec("library_reservations_total", "Reservations by outcome.", "outcome",
+			ReservationPlaced, ReservationRejected, ReservationFulfilled, ReservationExpired, ReservationCancelled),
+		lockWait: metrics.NewHistogram("library_lock_wait_seconds", "Time spent waiting for the library lock."),
+	}
+}
+
+// RegisterMetrics adds the library's metrics to r.
+func (l *Library) RegisterMetrics(r *metrics.Registry) {
+	r.Register(l.metrics.borrows, l.metrics.returns, l.metrics.reservations, l.metrics.lockWait)
+}
+
+// lock acquires l.mu and records how long that took. Lock wait is measured
+// on the wall clock, not l.clock, since it is real contention.
+func (l *Library) lock() {
+	start := time.Now()
+	l.mu.Lock()
+	l.metrics.lockWait.Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"library_management/events"
+	"library_management/models"
+	"library_management/recommend"
+)
+
+// DefaultRecommendations is how many books a recommendation query returns
+// when no limit is given.
+const DefaultRecommendations = 5
+
+// Recommendation is a suggested book. Score is the number of readers it
+// shares with the book (or the member's books) it was recommended for.
+type Recommendation struct {
+	Book  models.Book
+	Score int
+}
+
+// AlsoBorrowed returns the books most often borrowed by members who borrowed
+// bookID, best first. limit <= 0 means DefaultRecommendations.
+func (l *Library) AlsoBorrowed(bookID int, limit int) ([]Recommendation, error) {
+	l.lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.Books[bookID]; !exists {
+		return nil, ErrBookNotFound
+	}
+	return l.recommendations(l.history.AlsoBorrowed(bookID), limit), nil
+}
+
+// RecommendFor suggests books for a member based on what readers of their
+// past loans also borrowed, leaving out books they have already borrowed.
+// Members without a loan history get the most widely read books. limit <= 0
+// means DefaultRecommendations.
+func (l *Library) RecommendFor(memberID int, limit int) ([]Recommendation, error) {
+	l.lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.Members[memberID]; !exists {
+		return nil, ErrMemberNotFound
+	}
+	return l.recommendations(l.history.ForMember(memberID), limit), nil
+}
+
+// LoadHistory adds the borrows among past events, such as those of the audit
+// log, to the history recommendations are based on, and returns how many it
+// found. Loading a borrow that is already known has no effect.
+func (l *Library) LoadHistory(past []events.Event) int {
+	l.lock()
+	defer l.mu.Unlock()
+
+	loaded := 0
+	for _, e := range past {
+		if e.Type == events.BookBorrowed {
+			l.history.Record(e.MemberID, e.BookID)
+			loaded++
+		}
+	}
+	return loaded
+}
+
+// recommendations resolves scored book IDs to books, skipping books that
+// were removed or can no longer be lent. Callers hold l.mu.
+func (l *Library) recommendations(scores []recommend.Score, limit int) []Recommendation {
+	if limit <= 0 {
+		limit = DefaultRecommendations
+	}
+	recs := []Recommendation{}
+	for _, s := range scores {
+		if len(recs) == limit {
+			break
+		}
+		book, exists := l.Books[s.BookID]
+		if !exists || book.Status == models.StatusLost || book.Status == models.StatusWithdrawn {
+			continue
+		}
+		recs = append(recs, Recommendation{Book: book, Score: s.Score})
+	}
+	return recs
+}
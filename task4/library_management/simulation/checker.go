@@ -0,0 +1,84 @@
+package simulation
+
+import (
+	"fmt"
+	"library_management/events"
+	"library_management/services"
+	"sync"
+)
+
+// maxViolations caps how many violations are kept for the report.
+const maxViolations = 50
+
+// checker verifies library invariants from the event stream and from
+// periodic integrity checks.
+type checker struct {
+	borrowedBy map[int]int // book ID -> member currently holding it
+	reservedBy map[int]int // book ID -> member holding a reservation
+	violations []string
+	total      int
+	mu         sync.Mutex // Protects violations and total
+}
+
+func newChecker() *checker {
+	return &checker{
+		borrowedBy: make(map[int]int),
+		reservedBy: make(map[int]int),
+	}
+}
+
+// observe replays one event against the expected state. Events arrive in
+// the order the library applied them, so any disagreement is a real bug:
+// a book borrowed twice, returned by someone who does not have it, or
+// borrowed by someone other than the member who reserved it.
+func (c *checker) observe(e events.Event) {
+	switch e.Type {
+	case events.BookBorrowed:
+		if holder, ok := c.borrowedBy[e.BookID]; ok {
+			c.violate("book %d borrowed by member %d while on loan to member %d", e.BookID, e.MemberID, holder)
+		}
+		if holder, ok := c.reservedBy[e.BookID]; ok && holder != e.MemberID {
+			c.violate("book %d borrowed by member %d while reserved for member %d", e.BookID, e.MemberID, holder)
+		}
+		delete(c.reservedBy, e.BookID)
+		c.borrowedBy[e.BookID] = e.MemberID
+	case events.BookReturned:
+		if holder := c.borrowedBy[e.BookID]; holder != e.MemberID {
+			c.violate("book %d returned by member %d but on loan to member %d", e.BookID, e.MemberID, holder)
+		}
+		delete(c.borrowedBy, e.BookID)
+	case events.BookReserved:
+		if holder, ok := c.borrowedBy[e.BookID]; ok {
+			c.violate("book %d reserved by member %d while on loan to member %d", e.BookID, e.MemberID, holder)
+		}
+		if holder, ok := c.reservedBy[e.BookID]; ok {
+			c.violate("book %d reserved by member %d while reserved for member %d", e.BookID, e.MemberID, holder)
+		}
+		c.reservedBy[e.BookID] = e.MemberID
+	case events.ReservationExpired, events.ReservationCancelled:
+		delete(c.reservedBy, e.BookID)
+	}
+}
+
+// integrity records the issues found by Library.CheckIntegrity.
+func (c *checker) integrity(issues []services.IntegrityIssue) {
+	for _, issue := range issues {
+		c.violate("integrity: %s", issue)
+	}
+}
+
+func (c *checker) violate(format string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total++
+	if len(c.violations) < maxViolations {
+		c.violations = append(c.violations, fmt.Sprintf(format, args...))
+	}
+}
+
+// violationList returns the recorded violations and how many there were.
+func (c *checker) violationList() Violations {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Violations{Total: c.total, Samples: append([]string{}, c.violations...)}
+}
@@ -0,0 +1,128 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"library_management/concurrency"
+	"library_management/services"
+	"sort"
+	"time"
+)
+
+// Violations are invariant breaches seen during a run. Samples holds at most
+// the first 50 descriptions.
+type Violations struct {
+	Total   int
+	Samples []string
+}
+
+// OpStats summarizes one kind of operation.
+type OpStats struct {
+	Kind      concurrency.CommandKind
+	Count     int
+	Succeeded int
+	Timeouts  int
+	P50       time.Duration
+	P90       time.Duration
+	P99       time.Duration
+	Max       time.Duration
+}
+
+// Result is the outcome of a simulation run.
+type Result struct {
+	Config     Config
+	Elapsed    time.Duration
+	Operations int
+	Throughput float64 // Operations per second
+	Ops        []OpStats
+	Violations Violations
+	Integrity  []services.IntegrityIssue // Found by the final check, after the pool drained; also counted in Violations
+}
+
+// OK reports whether the run finished without invariant violations.
+func (r Result) OK() bool {
+	return r.Violations.Total == 0
+}
+
+func newResult(cfg Config, elapsed time.Duration, samples []sample, violations Violations) Result {
+	byKind := make(map[concurrency.CommandKind][]sample)
+	for _, s := range samples {
+		byKind[s.kind] = append(byKind[s.kind], s)
+	}
+
+	res := Result{
+		Config:     cfg,
+		Elapsed:    elapsed,
+		Operations: len(samples),
+		Violations: violations,
+	}
+	if elapsed > 0 {
+		res.Throughput = float64(len(samples)) / elapsed.Seconds()
+	}
+	for _, kind := range []concurrency.CommandKind{concurrency.CommandBorrow, concurrency.CommandReturn, concurrency.CommandReserve} {
+		ss := byKind[kind]
+		if len(ss) == 0 {
+			continue
+		}
+		stats := OpStats{Kind: kind, Count: len(ss)}
+		latencies := make([]time.Duration, len(ss))
+		for i, s := range ss {
+			latencies[i] = s.latency
+			switch {
+			case s.err == nil:
+				stats.Succeeded++
+			case isTimeout(s.err):
+				stats.Timeouts++
+			}
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		stats.P50 = percentile(latencies, 0.50)
+		stats.P90 = percentile(latencies, 0.90)
+		stats.P99 = percentile(latencies, 0.99)
+		stats.Max = latencies[len(latencies)-1]
+		res.Ops = append(res.Ops, stats)
+	}
+	return res
+}
+
+// WriteText prints the result.
+func (r Result) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Simulation: %d members, %d books, %d workers, seed %d\n",
+		r.Config.Members, r.Config.Books, r.Config.Workers, r.Config.Seed)
+	fmt.Fprintf(w, "Ran %s: %d operations, %.0f ops/s\n\n", r.Elapsed.Round(time.Millisecond), r.Operations, r.Throughput)
+	fmt.Fprintf(w, "%-8s %8s %9s %8s %10s %10s %10s %10s\n", "op", "count", "succeeded", "timeouts", "p50", "p90", "p99", "max")
+	for _, op := range r.Ops {
+		fmt.Fprintf(w, "%-8s %8d %9d %8d %10s %10s %10s %10s\n",
+			op.Kind, op.Count, op.Succeeded, op.Timeouts, round(op.P50), round(op.P90), round(op.P99), round(op.Max))
+	}
+	fmt.Fprintln(w)
+	if r.OK() {
+		fmt.Fprintln(w, "Invariants held: no violations.")
+		return
+	}
+	fmt.Fprintf(w, "Invariant violations: %d\n", r.Violations.Total)
+	for _, v := range r.Violations.Samples {
+		fmt.Fprintln(w, "-", v)
+	}
+}
+
+// percentile returns the p-th percentile of sorted latencies (nearest rank).
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
+
+func isTimeout(err error) bool {
+	return errors.Is(err, concurrency.ErrRequestTimeout)
+}
@@ -0,0 +1,188 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"library_management/clock"
+	"library_management/concurrency"
+	"library_management/models"
+	"library_management/services"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Config controls a simulation run.
+type Config struct {
+	Members        int           // Number of virtual members acting concurrently
+	Books          int           // Number of books in the simulated catalog
+	Duration       time.Duration // How long members keep acting
+	Workers        int           // Command pool workers
+	QueueSize      int           // Pending commands buffered per worker
+	CheckInterval  time.Duration // How often the integrity checker runs
+	RequestTimeout time.Duration // Deadline for each command
+	Seed           int64         // Random seed; runs with the same seed pick the same operations
+	Clock          clock.Clock   // Library clock for loans and holds (default the wall clock)
+}
+
+// withDefaults fills in unset fields.
+func (c Config) withDefaults() Config {
+	if c.Members <= 0 {
+		c.Members = 20
+	}
+	if c.Books <= 0 {
+		c.Books = 10
+	}
+	if c.Duration <= 0 {
+		c.Duration = 10 * time.Second
+	}
+	if c.Workers <= 0 {
+		c.Workers = 4
+	}
+	if c.QueueSize < 0 {
+		c.QueueSize = 0
+	}
+	if c.CheckInterval <= 0 {
+		c.CheckInterval = 100 * time.Millisecond
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = time.Second
+	}
+	if c.Seed == 0 {
+		c.Seed = time.Now().UnixNano()
+	}
+	if c.Clock == nil {
+		c.Clock = clock.Real{}
+	}
+	return c
+}
+
+// sample is the outcome of one simulated operation.
+type sample struct {
+	kind    concurrency.CommandKind
+	latency time.Duration
+	err     error
+}
+
+// Run builds a fresh library, lets cfg.Members virtual members borrow,
+// return and reserve books at random for cfg.Duration, and checks the
+// library's invariants while they do. It returns once every member has
+// stopped and the pool has drained.
+func Run(ctx context.Context, cfg Config) Result {
+	cfg = cfg.withDefaults()
+
+	library := services.NewLibraryWithClock(cfg.Clock)
+	for i := 1; i <= cfg.Members; i++ {
+		library.AddMember(models.Member{ID: i, Name: fmt.Sprintf("Member %d", i), Loans: []models.Loan{}})
+	}
+	for i := 1; i <= cfg.Books; i++ {
+		library.AddBook(models.Book{ID: i, Title: fmt.Sprintf("Book %d", i), Author: "Simulator"})
+	}
+	pool := concurrency.NewCommandPool(library, cfg.Workers, cfg.QueueSize)
+
+	checker := newChecker()
+	stream, unsubscribe := library.Subscribe()
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		for e := range stream {
+			checker.observe(e)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	// Periodically verify that books and members agree.
+	checkDone := make(chan struct{})
+	go func() {
+		defer close(checkDone)
+		ticker := time.NewTicker(cfg.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				checker.integrity(library.CheckIntegrity(false))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	samples := make(chan []sample, cfg.Members)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 1; i <= cfg.Members; i++ {
+		wg.Add(1)
+		rng := rand.New(rand.NewSource(cfg.Seed + int64(i)))
+		go func(memberID int) {
+			defer wg.Done()
+			samples <- runMember(ctx, pool, memberID, cfg, rng)
+		}(i)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	close(samples)
+	<-checkDone
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	pool.Shutdown(shutdownCtx)
+	final := library.CheckIntegrity(false)
+	checker.integrity(final)
+	unsubscribe()
+	<-streamDone
+
+	var all []sample
+	for s := range samples {
+		all = append(all, s...)
+	}
+	res := newResult(cfg, elapsed, all, checker.violationList())
+	res.Integrity = final
+	return res
+}
+
+// runMember performs random operations until ctx ends. Each member remembers
+// the books it borrowed so that returns are usually valid, while borrows and
+// reservations target any book and so contend with other members.
+func runMember(ctx context.Context, pool *concurrency.CommandPool, memberID int, cfg Config, rng *rand.Rand) []sample {
+	var samples []sample
+	var loans []int
+	for ctx.Err() == nil {
+		cmd := concurrency.Command{MemberID: memberID, BookID: rng.Intn(cfg.Books) + 1}
+		switch n := rng.Intn(10); {
+		case n < 4:
+			cmd.Kind = concurrency.CommandBorrow
+		case n < 7 && len(loans) > 0:
+			i := rng.Intn(len(loans))
+			cmd.Kind, cmd.BookID = concurrency.CommandReturn, loans[i]
+		default:
+			cmd.Kind = concurrency.CommandReserve
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, cfg.RequestTimeout)
+		began := time.Now()
+		err := pool.Do(reqCtx, cmd)
+		latency := time.Since(began)
+		cancel()
+		if err != nil && ctx.Err() != nil {
+			break // The run ended while this command was waiting.
+		}
+		samples = append(samples, sample{kind: cmd.Kind, latency: latency, err: err})
+
+		if err == nil {
+			switch cmd.Kind {
+			case concurrency.CommandBorrow:
+				loans = append(loans, cmd.BookID)
+			case concurrency.CommandReturn:
+				for i, id := range loans {
+					if id == cmd.BookID {
+						loans = append(loans[:i], loans[i+1:]...)
+						break
+					}
+				}
+			}
+		}
+	}
+	return samples
+}
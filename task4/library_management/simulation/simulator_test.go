@@ -0,0 +1,73 @@
+package simulation
+
+import (
+	"context"
+	"library_management/clock"
+	"library_management/concurrency"
+	"library_management/services"
+	"testing"
+	"time"
+)
+
+func TestRunKeepsInvariants(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	cfg := Config{
+		Members:       8,
+		Books:         4, // Few books, so members contend for them
+		Duration:      200 * time.Millisecond,
+		Workers:       2,
+		QueueSize:     4,
+		CheckInterval: 10 * time.Millisecond,
+		Seed:          42,
+		Clock:         fake,
+	}
+
+	// Let reservations expire while members borrow and return, so expiries
+	// race with the pool.
+	stop := make(chan struct{})
+	advanced := make(chan struct{})
+	go func() {
+		defer close(advanced)
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fake.Advance(services.ReservationHold / 2)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	result := Run(context.Background(), cfg)
+	close(stop)
+	<-advanced
+
+	if !result.OK() {
+		t.Fatalf("%d violations: %v", result.Violations.Total, result.Violations.Samples)
+	}
+	if len(result.Integrity) != 0 {
+		t.Fatalf("final integrity check: %v", result.Integrity)
+	}
+	if result.Config.Seed != 42 {
+		t.Errorf("seed = %d, want 42", result.Config.Seed)
+	}
+	if result.Operations == 0 {
+		t.Fatal("no operations ran")
+	}
+	total := 0
+	for _, op := range result.Ops {
+		total += op.Count
+		if op.Succeeded > op.Count || op.P50 > op.Max {
+			t.Errorf("inconsistent stats for %s: %+v", op.Kind, op)
+		}
+	}
+	if total != result.Operations {
+		t.Errorf("ops add up to %d, want %d", total, result.Operations)
+	}
+	for _, op := range result.Ops {
+		if op.Kind == concurrency.CommandBorrow && op.Succeeded == 0 {
+			t.Error("no borrow succeeded")
+		}
+	}
+}
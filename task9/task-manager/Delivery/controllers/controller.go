@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
-	"task_manager/Domain"   // Need Domain errors
-	"task_manager/Usecases" // Need usecase interfaces
+	"strings"
+	"time"
+	Domain "task_manager/Domain"     // Need Domain errors
+	Usecases "task_manager/Usecases" // Need usecase interfaces
 
 	"github.com/gin-gonic/gin"
 )
@@ -43,16 +48,23 @@ type TaskResponse struct {
 	ID          int    `json:"id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
-	DueDate     string `json:"due_date"`
+	DueDate     string `json:"due_date,omitempty"` // RFC 3339; omitted if the task has no due date
 	Status      string `json:"status"`
 }
 
+// TaskListResponse is one page of GET /tasks.
+type TaskListResponse struct {
+	Tasks      []TaskResponse `json:"tasks"`
+	Total      int            `json:"total"`                 // Matching tasks across all pages
+	NextCursor string         `json:"next_cursor,omitempty"` // Pass as ?cursor= for the next page
+}
+
 // CreateTaskRequest defines the expected body for creating a task.
 type CreateTaskRequest struct {
 	Title       string `json:"title" binding:"required"`
 	Description string `json:"description"`
-	DueDate     string `json:"due_date"` // Add validation tags if needed (e.g., format)
-	Status      string `json:"status"`   // Add validation tags if needed (e.g., enum)
+	DueDate     string `json:"due_date"` // RFC 3339 or YYYY-MM-DD; validated by the usecase
+	Status      string `json:"status"`   // pending (default), in-progress or completed
 }
 
 // UpdateTaskRequest defines the expected body for updating a task.
@@ -81,6 +93,16 @@ func NewController(userUC Usecases.UserUsecase, taskUC Usecases.TaskUsecase) *Co
 // --- Error Mapping Helper ---
 // Maps Domain/usecase errors to appropriate HTTP status codes and responses.
 func mapDomainErrorToHTTP(err error) (int, gin.H) {
+	var validationErr *Domain.ValidationError
+	if errors.As(err, &validationErr) {
+		// Report every invalid field so clients can highlight them
+		fields := make([]gin.H, len(validationErr.Fields))
+		for i, f := range validationErr.Fields {
+			fields[i] = gin.H{"field": f.Field, "message": f.Message}
+		}
+		return http.StatusBadRequest, gin.H{"error": "validation failed", "fields": fields}
+	}
+
 	switch err {
 	case Domain.ErrInvalidCredentials:
 		// Specific handling for login failure
@@ -92,7 +114,7 @@ func mapDomainErrorToHTTP(err error) (int, gin.H) {
 		return http.StatusNotFound, gin.H{"error": err.Error()}
 	case Domain.ErrForbidden:
 		return http.StatusForbidden, gin.H{"error": err.Error()}
-	case Domain.ErrBadRequest:
+	case Domain.ErrBadRequest, Domain.ErrInvalidCursor:
 		return http.StatusBadRequest, gin.H{"error": err.Error()}
 	case Domain.ErrInternalServer:
 		// Log the internal error details here (not shown for brevity)
@@ -181,11 +203,19 @@ func mapDomainTaskToResponse(task Domain.Task) TaskResponse {
 		ID:          task.ID,
 		Title:       task.Title,
 		Description: task.Description,
-		DueDate:     task.DueDate,
+		DueDate:     formatDueDate(task.DueDate),
 		Status:      task.Status,
 	}
 }
 
+// formatDueDate renders a due date as RFC 3339, or "" if there is none.
+func formatDueDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 // mapDomainTasksToResponse converts a slice of Domain.Task to []TaskResponse.
 func mapDomainTasksToResponse(tasks []Domain.Task) []TaskResponse {
 	responses := make([]TaskResponse, len(tasks))
@@ -196,15 +226,59 @@ func mapDomainTasksToResponse(tasks []Domain.Task) []TaskResponse {
 }
 
 // GetTasks handles GET /tasks (requires authentication via middleware).
+// Query parameters: status (pending, in-progress or completed), due_from and
+// due_to (RFC 3339 or YYYY-MM-DD, inclusive), title (substring), sort (id,
+// due_date or title; prefix with - for descending), limit (1 to 100, default
+// 20) and cursor (next_cursor of the previous page).
+// Responds with {"tasks": [...], "total": n, "next_cursor": "..."}.
 func (ctr *Controller) GetTasks(c *gin.Context) {
-	tasks, err := ctr.TaskUsecase.GetAllTasks()
+	query := Domain.TaskQuery{
+		Status:        c.Query("status"),
+		TitleContains: c.Query("title"),
+		Cursor:        c.Query("cursor"),
+	}
+	var err error
+	if query.DueFrom, err = Domain.ParseDueDate(c.Query("due_from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_from format"})
+		return
+	}
+	if query.DueTo, err = Domain.ParseDueDate(c.Query("due_to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_to format"})
+		return
+	}
+	if len(c.Query("due_to")) == len(Domain.DateOnlyLayout) {
+		// A date-only upper bound includes the whole day
+		query.DueTo = query.DueTo.Add(24*time.Hour - time.Nanosecond)
+	}
+	if sortBy := c.Query("sort"); sortBy != "" {
+		query.Descending = strings.HasPrefix(sortBy, "-")
+		query.SortBy = Domain.TaskSortField(strings.TrimPrefix(sortBy, "-"))
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+			return
+		}
+		if limit < 1 || limit > Domain.MaxTaskPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", Domain.MaxTaskPageSize)})
+			return
+		}
+		query.Limit = limit
+	}
+
+	page, err := ctr.TaskUsecase.ListTasks(query)
 	if err != nil {
 		status, body := mapDomainErrorToHTTP(err)
 		c.JSON(status, body)
 		return
 	}
 	// Map Domain tasks to response DTOs
-	c.JSON(http.StatusOK, mapDomainTasksToResponse(tasks))
+	c.JSON(http.StatusOK, TaskListResponse{
+		Tasks:      mapDomainTasksToResponse(page.Tasks),
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	})
 }
 
 // GetTask handles GET /tasks/:id (requires authentication via middleware).
@@ -269,6 +343,68 @@ func (ctr *Controller) UpdateTask(c *gin.Context) {
 	c.JSON(http.StatusOK, mapDomainTaskToResponse(updatedTask))
 }
 
+// PatchTask handles PATCH /tasks/:id (requires admin auth via middleware).
+// The body is a JSON Merge Patch (RFC 7396): fields that are present replace
+// the task's values, null clears a field and absent fields are left unchanged.
+func (ctr *Controller) PatchTask(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID format"})
+		return
+	}
+
+	patch, err := parseTaskMergePatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	updatedTask, err := ctr.TaskUsecase.PatchTask(id, patch)
+	if err != nil {
+		status, body := mapDomainErrorToHTTP(err)
+		c.JSON(status, body)
+		return
+	}
+	// Map Domain task to response DTO
+	c.JSON(http.StatusOK, mapDomainTaskToResponse(updatedTask))
+}
+
+// parseTaskMergePatch reads a merge patch body into a Domain.TaskPatch.
+// Members are decoded one by one, since a plain struct cannot tell an
+// absent field from a null one.
+func parseTaskMergePatch(c *gin.Context) (Domain.TaskPatch, error) {
+	var members map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&members); err != nil {
+		return Domain.TaskPatch{}, err
+	}
+	if members == nil {
+		return Domain.TaskPatch{}, errors.New("body must be a JSON object")
+	}
+
+	var patch Domain.TaskPatch
+	fields := map[string]**string{
+		"title":       &patch.Title,
+		"description": &patch.Description,
+		"due_date":    &patch.DueDate,
+		"status":      &patch.Status,
+	}
+	for name, raw := range members {
+		field, ok := fields[name]
+		if !ok {
+			return Domain.TaskPatch{}, fmt.Errorf("unknown field %q", name)
+		}
+		value := "" // null clears the field
+		if string(raw) != "null" {
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return Domain.TaskPatch{}, fmt.Errorf("field %q must be a string", name)
+			}
+		}
+		*field = &value
+	}
+	return patch, nil
+}
+
 // DeleteTask handles DELETE /tasks/:id (requires admin auth via middleware).
 func (ctr *Controller) DeleteTask(c *gin.Context) {
 	idStr := c.Param("id")
@@ -0,0 +1,187 @@
+package controllers_test // Use _test package for black-box testing of controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"task_manager/Delivery/controllers" // The package being tested
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// newTaskRouter serves the task routes without authentication, backed by an
+// in-memory repository holding the given tasks.
+func newTaskRouter(t *testing.T, tasks ...domain.Task) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	repo := repositories.NewInMemoryTaskRepository()
+	for _, task := range tasks {
+		_, err := repo.Save(task)
+		require.NoError(t, err)
+	}
+	ctr := controllers.NewController(nil, usecases.NewTaskUsecase(repo))
+
+	r := gin.New()
+	r.GET("/tasks", ctr.GetTasks)
+	r.GET("/tasks/:id", ctr.GetTask)
+	r.PATCH("/tasks/:id", ctr.PatchTask)
+	return r
+}
+
+// serve sends a request and returns the recorded response.
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestController_GetTasks(t *testing.T) {
+	r := newTaskRouter(t,
+		domain.Task{Title: "Write report", Status: domain.StatusPending},
+		domain.Task{Title: "Buy milk", Status: domain.StatusCompleted},
+		domain.Task{Title: "Write tests", Status: domain.StatusPending},
+	)
+
+	t.Run("Success - Page with total and cursor", func(t *testing.T) {
+		w := serve(r, http.MethodGet, "/tasks?status=pending&limit=1", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var page controllers.TaskListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		require.Len(t, page.Tasks, 1)
+		require.Equal(t, 1, page.Tasks[0].ID)
+		require.Equal(t, 2, page.Total)
+		require.NotEmpty(t, page.NextCursor)
+
+		w = serve(r, http.MethodGet, "/tasks?status=pending&limit=1&cursor="+page.NextCursor, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var last controllers.TaskListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &last))
+		require.Len(t, last.Tasks, 1)
+		require.Equal(t, 3, last.Tasks[0].ID)
+		require.Empty(t, last.NextCursor)
+	})
+
+	t.Run("Failure - Bad query parameters", func(t *testing.T) {
+		for _, query := range []string{
+			"status=done",
+			"status=PENDING",
+			"limit=0",
+			"limit=-5",
+			"limit=101",
+			"limit=ten",
+			"sort=priority",
+			"due_from=yesterday",
+			"cursor=not-a-cursor",
+		} {
+			w := serve(r, http.MethodGet, "/tasks?"+query, "")
+			require.Equal(t, http.StatusBadRequest, w.Code, "query %q: %s", query, w.Body.String())
+		}
+	})
+}
+
+func TestController_PatchTask(t *testing.T) {
+	due := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	original := controllers.TaskResponse{
+		ID:          1,
+		Title:       "Write report",
+		Description: "Quarterly numbers",
+		DueDate:     due.Format(time.RFC3339),
+		Status:      domain.StatusInProgress,
+	}
+	// changed returns the original task with edit applied.
+	changed := func(edit func(*controllers.TaskResponse)) controllers.TaskResponse {
+		task := original
+		edit(&task)
+		return task
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		wantCode int
+		want     controllers.TaskResponse // The stored task afterwards
+	}{
+		// Absent members leave their fields alone.
+		{"Success - Empty patch", "/tasks/1", `{}`, http.StatusOK, original},
+		{"Success - Title only", "/tasks/1", `{"title": "Write summary"}`, http.StatusOK,
+			changed(func(t *controllers.TaskResponse) { t.Title = "Write summary" })},
+		{"Success - Description set", "/tasks/1", `{"description": "Yearly numbers"}`, http.StatusOK,
+			changed(func(t *controllers.TaskResponse) { t.Description = "Yearly numbers" })},
+		{"Success - Due date set", "/tasks/1", `{"due_date": "2031-02-03"}`, http.StatusOK,
+			changed(func(t *controllers.TaskResponse) { t.DueDate = "2031-02-03T00:00:00Z" })},
+		// null clears a field.
+		{"Success - Description cleared", "/tasks/1", `{"description": null}`, http.StatusOK,
+			changed(func(t *controllers.TaskResponse) { t.Description = "" })},
+		{"Success - Due date cleared", "/tasks/1", `{"due_date": null}`, http.StatusOK,
+			changed(func(t *controllers.TaskResponse) { t.DueDate = "" })},
+		{"Success - Status reset to pending", "/tasks/1", `{"status": null}`, http.StatusOK,
+			changed(func(t *controllers.TaskResponse) { t.Status = domain.StatusPending })},
+		// Wrong types, unknown members and invalid values change nothing.
+		{"Failure - Description is a number", "/tasks/1", `{"description": 5}`, http.StatusBadRequest, original},
+		{"Failure - Due date is a boolean", "/tasks/1", `{"due_date": true}`, http.StatusBadRequest, original},
+		{"Failure - Due date is an object", "/tasks/1", `{"due_date": {}}`, http.StatusBadRequest, original},
+		{"Failure - Due date is malformed", "/tasks/1", `{"due_date": "soon"}`, http.StatusBadRequest, original},
+		{"Failure - Title null", "/tasks/1", `{"title": null, "description": "Dropped"}`, http.StatusBadRequest, original},
+		{"Failure - Title blank", "/tasks/1", `{"title": "  "}`, http.StatusBadRequest, original},
+		{"Failure - Unknown field", "/tasks/1", `{"priority": "high"}`, http.StatusBadRequest, original},
+		{"Failure - Body is not an object", "/tasks/1", `["title"]`, http.StatusBadRequest, original},
+		{"Failure - Body is null", "/tasks/1", `null`, http.StatusBadRequest, original},
+		{"Failure - Task not found", "/tasks/99", `{"title": "Nothing"}`, http.StatusNotFound, original},
+		{"Failure - Invalid ID", "/tasks/one", `{"title": "Nothing"}`, http.StatusBadRequest, original},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTaskRouter(t, domain.Task{
+				Title:       original.Title,
+				Description: original.Description,
+				DueDate:     due,
+				Status:      original.Status,
+			})
+
+			w := serve(r, http.MethodPatch, tt.path, tt.body)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode == http.StatusOK {
+				var got controllers.TaskResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				require.Equal(t, tt.want, got)
+			}
+
+			w = serve(r, http.MethodGet, "/tasks/1", "")
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var stored controllers.TaskResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+			require.Equal(t, tt.want, stored)
+		})
+	}
+
+	t.Run("Failure - Null title names the field", func(t *testing.T) {
+		r := newTaskRouter(t, domain.Task{Title: original.Title, Status: domain.StatusPending})
+		w := serve(r, http.MethodPatch, "/tasks/1", `{"title": null}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		var body struct {
+			Error  string `json:"error"`
+			Fields []struct {
+				Field string `json:"field"`
+			} `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, "validation failed", body.Error)
+		require.Len(t, body.Fields, 1)
+		require.Equal(t, "title", body.Fields[0].Field)
+	})
+}
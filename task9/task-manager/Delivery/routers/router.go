@@ -5,9 +5,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"task_manager/Delivery/controllers"
-	"task_manager/Infrastructure" // Need middleware + service implementations
-	"task_manager/Repositories"  // Need repository implementations
-	"task_manager/Usecases"      // Need usecase implementations
+	Infrastructure "task_manager/Infrastructure" // Need middleware + service implementations
+	Repositories "task_manager/Repositories"     // Need repository implementations
+	Usecases "task_manager/Usecases"             // Need usecase implementations
 )
 
 // SetupRouter initializes dependencies (DI) and configures Gin routes.
@@ -16,6 +16,7 @@ func SetupRouter() *gin.Engine {
 	jwtSecret := "your_very_secret_key_here_CHANGE_ME_IN_PRODUCTION"
 	jwtIssuer := "task-manager-api"
 	jwtExpiry := 24 * time.Hour // Token valid for 24 hours
+	rejectPastDueDates := true  // New tasks cannot already be overdue
 
 	// --- Dependency Injection ---
 
@@ -29,7 +30,11 @@ func SetupRouter() *gin.Engine {
 
 	// 3. Usecases (injecting repository and Infrastructure dependencies)
 	userUsecase := Usecases.NewUserUsecase(userRepo, passwordSvc, jwtSvc)
-	taskUsecase := Usecases.NewTaskUsecase(taskRepo)
+	var taskOpts []Usecases.TaskUsecaseOption
+	if rejectPastDueDates {
+		taskOpts = append(taskOpts, Usecases.WithRejectPastDueDates(time.Now))
+	}
+	taskUsecase := Usecases.NewTaskUsecase(taskRepo, taskOpts...)
 
 	// 4. Controllers (injecting usecase dependencies)
 	appController := controllers.NewController(userUsecase, taskUsecase)
@@ -68,6 +73,7 @@ func SetupRouter() *gin.Engine {
 			// Apply AdminOnly middleware *after* Authenticate
 			tasks.POST("", Infrastructure.AuthorizeAdmin(), appController.CreateTask)
 			tasks.PUT("/:id", Infrastructure.AuthorizeAdmin(), appController.UpdateTask)
+			tasks.PATCH("/:id", Infrastructure.AuthorizeAdmin(), appController.PatchTask)
 			tasks.DELETE("/:id", Infrastructure.AuthorizeAdmin(), appController.DeleteTask)
 		}
 
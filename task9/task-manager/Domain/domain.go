@@ -1,6 +1,11 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Constants for user roles
 const (
@@ -16,14 +21,92 @@ type User struct {
 	Role     string
 }
 
+// Task statuses. A task without a status is pending.
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in-progress"
+	StatusCompleted  = "completed"
+)
+
+// IsValidStatus reports whether status is one of the defined task statuses.
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusPending, StatusInProgress, StatusCompleted:
+		return true
+	}
+	return false
+}
+
 // Task represents the core task entity.
 // No json tags.
 type Task struct {
 	ID          int
 	Title       string
 	Description string
-	DueDate     string // Consider using time.Time in a real application
-	Status      string
+	DueDate     time.Time // Zero if the task has no due date
+	Status      string    // One of the Status constants
+}
+
+// DateOnlyLayout is the layout of due dates given without a time of day.
+const DateOnlyLayout = "2006-01-02"
+
+// ParseDueDate parses an RFC 3339 timestamp or a date-only YYYY-MM-DD value
+// (midnight UTC). An empty string is the zero time, i.e. no due date.
+func ParseDueDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(DateOnlyLayout, s)
+}
+
+// TaskPatch is a partial update of a task. Nil fields are left unchanged;
+// to clear a field, point it at an empty string. DueDate is parsed with
+// ParseDueDate.
+type TaskPatch struct {
+	Title       *string
+	Description *string
+	DueDate     *string
+	Status      *string
+}
+
+// --- Task listing ---
+
+// TaskSortField names the field tasks are ordered by when listed.
+type TaskSortField string
+
+const (
+	SortByID      TaskSortField = "id"
+	SortByDueDate TaskSortField = "due_date"
+	SortByTitle   TaskSortField = "title"
+)
+
+// Page size limits for task listings.
+const (
+	DefaultTaskPageSize = 20
+	MaxTaskPageSize     = 100
+)
+
+// TaskQuery selects, orders and pages the tasks returned by a listing.
+// Zero values mean "no filter".
+type TaskQuery struct {
+	Status        string        // Exact match; one of the Status constants
+	DueFrom       time.Time     // Inclusive lower bound on DueDate
+	DueTo         time.Time     // Inclusive upper bound on DueDate
+	TitleContains string        // Case-insensitive substring of Title
+	SortBy        TaskSortField // Defaults to SortByID; ties are broken by ID
+	Descending    bool
+	Limit         int    // Page size, at most MaxTaskPageSize
+	Cursor        string // Opaque; NextCursor of the previous page, empty for the first
+}
+
+// TaskPage is one page of a task listing.
+type TaskPage struct {
+	Tasks      []Task
+	Total      int    // Tasks matching the query across all pages
+	NextCursor string // Empty on the last page
 }
 
 // --- Domain specific errors ---
@@ -36,4 +119,45 @@ var (
 	ErrForbidden          = errors.New("forbidden access")
 	ErrInternalServer     = errors.New("internal server error") // For unexpected issues
 	ErrBadRequest         = errors.New("bad request")           // For invalid input structure/format
-)
\ No newline at end of file
+	ErrInvalidCursor      = errors.New("invalid pagination cursor")
+)
+
+// --- Validation errors ---
+
+// FieldError describes why one input field was rejected.
+type FieldError struct {
+	Field   string // Input name, e.g. "due_date"
+	Message string
+}
+
+// ValidationError lists every invalid field of a create or update request.
+// It matches ErrBadRequest with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Add records an invalid field.
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err returns e if any field was added, or nil.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = fmt.Sprintf("%s: %s", f.Field, f.Message)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Is makes errors.Is(err, ErrBadRequest) true for validation errors.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrBadRequest
+}
@@ -1,7 +1,7 @@
 package mocks
 
 import (
-	"task_manager/infrastructure" // Need JWTClaims definition
+	"task_manager/Infrastructure" // Need JWTClaims definition
 	"github.com/stretchr/testify/mock"
 )
 
@@ -1,7 +1,7 @@
 package mocks
 
 import (
-	"task_manager/domain"
+	"task_manager/Domain"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -75,6 +75,27 @@ func (_m *MockTaskRepository) FindAll() ([]domain.Task, error) {
 	return r0, r1
 }
 
+// Find provides a mock function with given fields: query
+func (_m *MockTaskRepository) Find(query domain.TaskQuery) (domain.TaskPage, error) {
+	ret := _m.Called(query)
+
+	var r0 domain.TaskPage
+	if rf, ok := ret.Get(0).(func(domain.TaskQuery) domain.TaskPage); ok {
+		r0 = rf(query)
+	} else {
+		r0 = ret.Get(0).(domain.TaskPage)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(domain.TaskQuery) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: task
 func (_m *MockTaskRepository) Update(task domain.Task) (domain.Task, error) {
 	ret := _m.Called(task)
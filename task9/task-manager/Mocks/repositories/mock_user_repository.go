@@ -1,7 +1,7 @@
 package mocks
 
 import (
-	"task_manager/domain"
+	"task_manager/Domain"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -1,6 +1,10 @@
 package repositories
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
 	"sync"
 	"task_manager/Domain" // Depends only on Domain
 )
@@ -11,6 +15,10 @@ type TaskRepository interface {
 	Save(task domain.Task) (domain.Task, error)
 	FindByID(id int) (domain.Task, error)
 	FindAll() ([]domain.Task, error)
+	// Find returns the page of tasks matching query. query.Limit must already
+	// be within (0, domain.MaxTaskPageSize]; a malformed cursor returns
+	// domain.ErrInvalidCursor.
+	Find(query domain.TaskQuery) (domain.TaskPage, error)
 	Update(task domain.Task) (domain.Task, error)
 	Delete(id int) error
 }
@@ -67,6 +75,118 @@ func (r *inMemoryTaskRepository) FindAll() ([]domain.Task, error) {
 	return allTasks, nil
 }
 
+// Find filters, sorts and pages the stored tasks.
+// Cursors are keyset positions (the sort key and ID of the last task on the
+// previous page), so pages stay consistent when tasks are added or removed.
+func (r *inMemoryTaskRepository) Find(query domain.TaskQuery) (domain.TaskPage, error) {
+	var after *taskCursor
+	if query.Cursor != "" {
+		c, err := decodeTaskCursor(query.Cursor)
+		if err != nil {
+			return domain.TaskPage{}, err
+		}
+		after = &c
+	}
+
+	r.mutex.RLock() // Read lock sufficient
+	matching := make([]domain.Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		if matchesTaskQuery(task, query) {
+			matching = append(matching, task)
+		}
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(matching, func(i, j int) bool {
+		return taskBefore(query, keyOf(query.SortBy, matching[i]), keyOf(query.SortBy, matching[j]))
+	})
+
+	start := 0
+	if after != nil {
+		// First task that sorts after the cursor position
+		start = sort.Search(len(matching), func(i int) bool {
+			return taskBefore(query, *after, keyOf(query.SortBy, matching[i]))
+		})
+	}
+	end := start + query.Limit
+	if end > len(matching) {
+		end = len(matching)
+	}
+
+	if end < start {
+		end = start // A Limit below 1 gives an empty page
+	}
+
+	page := domain.TaskPage{Tasks: matching[start:end], Total: len(matching)}
+	if end > start && end < len(matching) {
+		page.NextCursor = encodeTaskCursor(keyOf(query.SortBy, matching[end-1]))
+	}
+	return page, nil
+}
+
+func matchesTaskQuery(task domain.Task, query domain.TaskQuery) bool {
+	if query.Status != "" && task.Status != query.Status {
+		return false
+	}
+	// Tasks without a due date never match a due-date range.
+	if !query.DueFrom.IsZero() && (task.DueDate.IsZero() || task.DueDate.Before(query.DueFrom)) {
+		return false
+	}
+	if !query.DueTo.IsZero() && (task.DueDate.IsZero() || task.DueDate.After(query.DueTo)) {
+		return false
+	}
+	if query.TitleContains != "" && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(query.TitleContains)) {
+		return false
+	}
+	return true
+}
+
+// dueDateKeyLayout is fixed-width, so due date keys sort chronologically as strings.
+const dueDateKeyLayout = "2006-01-02T15:04:05.000000000Z"
+
+// taskCursor is a position in a sorted listing.
+type taskCursor struct {
+	Key string `json:"k"` // Sort field value; empty when sorting by ID
+	ID  int    `json:"id"`
+}
+
+func keyOf(field domain.TaskSortField, task domain.Task) taskCursor {
+	switch field {
+	case domain.SortByDueDate:
+		if task.DueDate.IsZero() {
+			return taskCursor{ID: task.ID} // Tasks without a due date sort first
+		}
+		return taskCursor{Key: task.DueDate.UTC().Format(dueDateKeyLayout), ID: task.ID}
+	case domain.SortByTitle:
+		return taskCursor{Key: strings.ToLower(task.Title), ID: task.ID}
+	default:
+		return taskCursor{ID: task.ID}
+	}
+}
+
+// taskBefore reports whether a sorts before b in the query's order.
+func taskBefore(query domain.TaskQuery, a, b taskCursor) bool {
+	less := a.Key < b.Key || (a.Key == b.Key && a.ID < b.ID)
+	if query.Descending {
+		return !less && a != b
+	}
+	return less
+}
+
+func encodeTaskCursor(c taskCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTaskCursor(s string) (taskCursor, error) {
+	var c taskCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &c) != nil {
+		return taskCursor{}, domain.ErrInvalidCursor
+	}
+	return c, nil
+}
+
 // Update modifies an existing task.
 func (r *inMemoryTaskRepository) Update(updatedTask domain.Task) (domain.Task, error) {
 	r.mutex.Lock() // Write lock needed
@@ -0,0 +1,193 @@
+package repositories_test // Use _test package for black-box testing of repositories
+
+import (
+	"testing"
+	"time"
+	"task_manager/Domain"
+	"task_manager/Repositories" // The package being tested
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// newTaskRepository returns a repository holding tasks 1 to 5. Tasks 2 and 5
+// have no due date; tasks 1 and 4 are due at the same time.
+func newTaskRepository(t *testing.T) repositories.TaskRepository {
+	t.Helper()
+	repo := repositories.NewInMemoryTaskRepository()
+	for _, task := range []domain.Task{
+		{Title: "Write report", DueDate: date(time.March, 1), Status: domain.StatusPending},
+		{Title: "buy milk", Status: domain.StatusCompleted},
+		{Title: "Alpha", DueDate: date(time.January, 15), Status: domain.StatusPending},
+		{Title: "Write tests", DueDate: date(time.March, 1), Status: domain.StatusInProgress},
+		{Title: "Zebra", Status: domain.StatusPending},
+	} {
+		_, err := repo.Save(task)
+		require.NoError(t, err)
+	}
+	return repo
+}
+
+// findAll follows NextCursor from the first page to the last and returns the
+// IDs in listing order, checking every page's size and Total on the way.
+func findAll(t *testing.T, repo repositories.TaskRepository, query domain.TaskQuery) []int {
+	t.Helper()
+	var ids []int
+	total := -1
+	for page := 0; ; page++ {
+		result, err := repo.Find(query)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(result.Tasks), query.Limit)
+		if total < 0 {
+			total = result.Total
+		}
+		require.Equal(t, total, result.Total, "Total changed on page %d", page)
+		for _, task := range result.Tasks {
+			ids = append(ids, task.ID)
+		}
+		if result.NextCursor == "" {
+			require.Len(t, ids, total)
+			return ids
+		}
+		require.Len(t, result.Tasks, query.Limit, "short page %d has a next cursor", page)
+		query.Cursor = result.NextCursor
+	}
+}
+
+func TestInMemoryTaskRepository_FindOrder(t *testing.T) {
+	repo := newTaskRepository(t)
+
+	tests := []struct {
+		name  string
+		query domain.TaskQuery
+		want  []int
+	}{
+		{"ID", domain.TaskQuery{}, []int{1, 2, 3, 4, 5}},
+		{"ID descending", domain.TaskQuery{Descending: true}, []int{5, 4, 3, 2, 1}},
+		// Tasks without a due date come first, by ID; equal due dates by ID.
+		{"Due date", domain.TaskQuery{SortBy: domain.SortByDueDate}, []int{2, 5, 3, 1, 4}},
+		// Descending reverses the whole order, ties and missing due dates included.
+		{"Due date descending", domain.TaskQuery{SortBy: domain.SortByDueDate, Descending: true}, []int{4, 1, 3, 5, 2}},
+		// Titles compare case-insensitively.
+		{"Title", domain.TaskQuery{SortBy: domain.SortByTitle}, []int{3, 2, 1, 4, 5}},
+		{"Title descending", domain.TaskQuery{SortBy: domain.SortByTitle, Descending: true}, []int{5, 4, 1, 2, 3}},
+		{"Filtered", domain.TaskQuery{Status: domain.StatusPending, SortBy: domain.SortByDueDate}, []int{5, 3, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every page size must give the same order.
+			for limit := 1; limit <= len(tt.want)+1; limit++ {
+				query := tt.query
+				query.Limit = limit
+				require.Equal(t, tt.want, findAll(t, repo, query), "limit %d", limit)
+			}
+		})
+	}
+}
+
+func TestInMemoryTaskRepository_FindTotal(t *testing.T) {
+	repo := newTaskRepository(t)
+
+	page, err := repo.Find(domain.TaskQuery{Status: domain.StatusPending, Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, 3, page.Total) // All matching tasks, not just this page
+	require.Len(t, page.Tasks, 1)
+	require.NotEmpty(t, page.NextCursor)
+
+	// Tasks without a due date never match a due-date range.
+	page, err = repo.Find(domain.TaskQuery{DueTo: date(time.December, 31), Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 3, page.Total)
+	require.Empty(t, page.NextCursor)
+
+	page, err = repo.Find(domain.TaskQuery{TitleContains: "nothing", Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 0, page.Total)
+	require.Empty(t, page.Tasks)
+	require.Empty(t, page.NextCursor)
+}
+
+func TestInMemoryTaskRepository_FindEmptyPage(t *testing.T) {
+	repo := newTaskRepository(t)
+
+	// A Limit below 1 gives an empty page and no cursor rather than panicking.
+	for _, limit := range []int{0, -1} {
+		page, err := repo.Find(domain.TaskQuery{Limit: limit})
+		require.NoError(t, err, "limit %d", limit)
+		require.Empty(t, page.Tasks, "limit %d", limit)
+		require.Equal(t, 5, page.Total, "limit %d", limit)
+		require.Empty(t, page.NextCursor, "limit %d", limit)
+	}
+
+	// A cursor past the last task gives an empty last page.
+	page, err := repo.Find(domain.TaskQuery{SortBy: domain.SortByTitle, Descending: true, Limit: 4})
+	require.NoError(t, err)
+	last, err := repo.Find(domain.TaskQuery{SortBy: domain.SortByTitle, Descending: true, Limit: 4, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, last.Tasks, 1)
+	require.NoError(t, repo.Delete(last.Tasks[0].ID))
+	empty, err := repo.Find(domain.TaskQuery{SortBy: domain.SortByTitle, Descending: true, Limit: 4, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Empty(t, empty.Tasks)
+	require.Equal(t, 4, empty.Total)
+	require.Empty(t, empty.NextCursor)
+}
+
+func TestInMemoryTaskRepository_FindKeysetCursor(t *testing.T) {
+	t.Run("Success - Changes between pages neither repeat nor skip tasks", func(t *testing.T) {
+		repo := newTaskRepository(t)
+		query := domain.TaskQuery{SortBy: domain.SortByDueDate, Limit: 3}
+
+		page, err := repo.Find(query)
+		require.NoError(t, err)
+		require.Equal(t, []domain.Task{mustFind(t, repo, 2), mustFind(t, repo, 5), mustFind(t, repo, 3)}, page.Tasks)
+
+		// Remove the last task of the page, which the cursor points at, and
+		// add one task that sorts before the cursor and one after it.
+		require.NoError(t, repo.Delete(3))
+		_, err = repo.Save(domain.Task{Title: "Early", DueDate: date(time.January, 1)})
+		require.NoError(t, err)
+		_, err = repo.Save(domain.Task{Title: "Late", DueDate: date(time.June, 1)})
+		require.NoError(t, err)
+
+		query.Cursor = page.NextCursor
+		next, err := repo.Find(query)
+		require.NoError(t, err)
+		require.Equal(t, []domain.Task{mustFind(t, repo, 1), mustFind(t, repo, 4), mustFind(t, repo, 7)}, next.Tasks)
+		require.Equal(t, 6, next.Total) // Total counts the new task before the cursor too
+		require.Empty(t, next.NextCursor)
+	})
+
+	t.Run("Success - Descending pages continue in reverse order", func(t *testing.T) {
+		repo := newTaskRepository(t)
+		page, err := repo.Find(domain.TaskQuery{SortBy: domain.SortByDueDate, Descending: true, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, 4, page.Tasks[0].ID)
+		require.Equal(t, 1, page.Tasks[1].ID)
+
+		next, err := repo.Find(domain.TaskQuery{SortBy: domain.SortByDueDate, Descending: true, Limit: 2, Cursor: page.NextCursor})
+		require.NoError(t, err)
+		require.Equal(t, 3, next.Tasks[0].ID)
+		require.Equal(t, 5, next.Tasks[1].ID)
+		require.Equal(t, 5, next.Total)
+	})
+
+	t.Run("Failure - Malformed cursor", func(t *testing.T) {
+		repo := newTaskRepository(t)
+		for _, cursor := range []string{"not base64!", "bm90IGpzb24"} { // The second is "not json"
+			_, err := repo.Find(domain.TaskQuery{Limit: 2, Cursor: cursor})
+			require.ErrorIs(t, err, domain.ErrInvalidCursor, "cursor %q", cursor)
+		}
+	})
+}
+
+func mustFind(t *testing.T, repo repositories.TaskRepository, id int) domain.Task {
+	t.Helper()
+	task, err := repo.FindByID(id)
+	require.NoError(t, err)
+	return task
+}
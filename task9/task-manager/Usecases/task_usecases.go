@@ -1,8 +1,10 @@
 package usecases
 
 import (
+	"strings"
 	"task_manager/Domain"
 	"task_manager/Repositories" // Depends on Repository interface
+	"time"
 )
 
 // --- Task Usecase Interface ---
@@ -11,7 +13,9 @@ type TaskUsecase interface {
 	CreateTask(title, description, dueDate, status string) (domain.Task, error)
 	GetTaskByID(id int) (domain.Task, error)
 	GetAllTasks() ([]domain.Task, error)
+	ListTasks(query domain.TaskQuery) (domain.TaskPage, error)
 	UpdateTask(id int, title, description, dueDate, status string) (domain.Task, error)
+	PatchTask(id int, patch domain.TaskPatch) (domain.Task, error)
 	DeleteTask(id int) error
 	// Note: Authorization (who can call these) is handled by the delivery layer.
 }
@@ -19,26 +23,78 @@ type TaskUsecase interface {
 // --- Task Usecase Implementation ---
 type taskUsecase struct {
 	taskRepo repositories.TaskRepository // Interface dependency
+	now      func() time.Time            // Set to reject past due dates on create
+}
+
+// TaskUsecaseOption configures a TaskUsecase.
+type TaskUsecaseOption func(*taskUsecase)
+
+// WithRejectPastDueDates makes CreateTask reject due dates before the start
+// of the current day (UTC), as reported by now.
+func WithRejectPastDueDates(now func() time.Time) TaskUsecaseOption {
+	return func(uc *taskUsecase) { uc.now = now }
 }
 
 // NewTaskUsecase creates a new TaskUsecase instance.
-func NewTaskUsecase(taskRepo repositories.TaskRepository) TaskUsecase {
-	return &taskUsecase{taskRepo: taskRepo}
+func NewTaskUsecase(taskRepo repositories.TaskRepository, opts ...TaskUsecaseOption) TaskUsecase {
+	uc := &taskUsecase{taskRepo: taskRepo}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// --- Validation helpers ---
+// Each records problems in v under the request field name.
+
+func validateTitle(v *domain.ValidationError, title string) {
+	if strings.TrimSpace(title) == "" {
+		v.Add("title", "is required")
+	}
+}
+
+func parseDueDate(v *domain.ValidationError, dueDate string) time.Time {
+	t, err := domain.ParseDueDate(dueDate)
+	if err != nil {
+		v.Add("due_date", "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	return t
+}
+
+// parseStatus defaults an empty status to pending.
+func parseStatus(v *domain.ValidationError, status string) string {
+	if status == "" {
+		return domain.StatusPending
+	}
+	if !domain.IsValidStatus(status) {
+		v.Add("status", "must be one of pending, in-progress, completed")
+	}
+	return status
 }
 
 // CreateTask handles the business logic for creating a new task.
+// Invalid input returns a *domain.ValidationError listing every bad field.
 func (uc *taskUsecase) CreateTask(title, description, dueDate, status string) (domain.Task, error) {
-	// 1. Basic Input Validation (can be more complex)
-	if title == "" {
-		return domain.Task{}, domain.ErrBadRequest // Use domain error
+	// 1. Validate and convert input
+	var v domain.ValidationError
+	validateTitle(&v, title)
+	due := parseDueDate(&v, dueDate)
+	status = parseStatus(&v, status)
+	if uc.now != nil && !due.IsZero() {
+		today := uc.now().UTC().Truncate(24 * time.Hour)
+		if due.Before(today) {
+			v.Add("due_date", "must not be in the past")
+		}
+	}
+	if err := v.Err(); err != nil {
+		return domain.Task{}, err
 	}
-	// Add more validation for dueDate format, status enum etc. if needed
 
 	// 2. Prepare domain task object
 	taskToSave := domain.Task{
 		Title:       title,
 		Description: description,
-		DueDate:     dueDate,
+		DueDate:     due,
 		Status:      status,
 		// ID assigned by repository
 	}
@@ -76,13 +132,53 @@ func (uc *taskUsecase) GetAllTasks() ([]domain.Task, error) {
 	return tasks, nil
 }
 
+// ListTasks returns one page of the tasks matching query.
+// A zero Limit uses domain.DefaultTaskPageSize.
+func (uc *taskUsecase) ListTasks(query domain.TaskQuery) (domain.TaskPage, error) {
+	// 1. Validate and normalize the query
+	switch query.SortBy {
+	case "":
+		query.SortBy = domain.SortByID
+	case domain.SortByID, domain.SortByDueDate, domain.SortByTitle:
+	default:
+		return domain.TaskPage{}, domain.ErrBadRequest
+	}
+	if query.Limit == 0 {
+		query.Limit = domain.DefaultTaskPageSize
+	}
+	if query.Limit < 0 || query.Limit > domain.MaxTaskPageSize {
+		return domain.TaskPage{}, domain.ErrBadRequest
+	}
+	if query.Status != "" && !domain.IsValidStatus(query.Status) {
+		return domain.TaskPage{}, domain.ErrBadRequest
+	}
+	if !query.DueFrom.IsZero() && !query.DueTo.IsZero() && query.DueFrom.After(query.DueTo) {
+		return domain.TaskPage{}, domain.ErrBadRequest
+	}
+
+	// 2. Let the repository filter, sort and page
+	page, err := uc.taskRepo.Find(query)
+	if err != nil {
+		if err == domain.ErrInvalidCursor {
+			return domain.TaskPage{}, err
+		}
+		// Log internal error (not shown)
+		return domain.TaskPage{}, domain.ErrInternalServer
+	}
+	return page, nil
+}
+
 // UpdateTask handles the business logic for updating an existing task.
+// Invalid input returns a *domain.ValidationError listing every bad field.
 func (uc *taskUsecase) UpdateTask(id int, title, description, dueDate, status string) (domain.Task, error) {
-	// 1. Basic Input Validation
-	if title == "" {
-		return domain.Task{}, domain.ErrBadRequest
+	// 1. Validate and convert input
+	var v domain.ValidationError
+	validateTitle(&v, title)
+	due := parseDueDate(&v, dueDate)
+	status = parseStatus(&v, status)
+	if err := v.Err(); err != nil {
+		return domain.Task{}, err
 	}
-	// Add more validation as needed
 
     // 2. Check if task exists (optional, depends if repo.Update checks)
     //    It's often good practice for the use case to ensure the entity exists first.
@@ -100,7 +196,7 @@ func (uc *taskUsecase) UpdateTask(id int, title, description, dueDate, status st
 		ID:          id, // Crucial: ID must be set for the update target
 		Title:       title,
 		Description: description,
-		DueDate:     dueDate,
+		DueDate:     due,
 		Status:      status,
 	}
 
@@ -118,6 +214,49 @@ func (uc *taskUsecase) UpdateTask(id int, title, description, dueDate, status st
 	return updatedTask, nil
 }
 
+// PatchTask applies only the fields set in patch to an existing task and
+// validates the result like UpdateTask does.
+func (uc *taskUsecase) PatchTask(id int, patch domain.TaskPatch) (domain.Task, error) {
+	// 1. Load the current task
+	task, err := uc.taskRepo.FindByID(id)
+	if err != nil {
+		if err == domain.ErrTaskNotFound {
+			return domain.Task{}, err // Propagate not found
+		}
+		return domain.Task{}, domain.ErrInternalServer
+	}
+
+	// 2. Apply and validate the provided fields
+	var v domain.ValidationError
+	if patch.Title != nil {
+		task.Title = *patch.Title
+		validateTitle(&v, task.Title)
+	}
+	if patch.Description != nil {
+		task.Description = *patch.Description
+	}
+	if patch.DueDate != nil {
+		task.DueDate = parseDueDate(&v, *patch.DueDate)
+	}
+	if patch.Status != nil {
+		task.Status = parseStatus(&v, *patch.Status)
+	}
+	if err := v.Err(); err != nil {
+		return domain.Task{}, err
+	}
+
+	// 3. Update via repository
+	updatedTask, err := uc.taskRepo.Update(task)
+	if err != nil {
+		if err == domain.ErrTaskNotFound {
+			return domain.Task{}, err
+		}
+		// Log internal error (not shown)
+		return domain.Task{}, domain.ErrInternalServer
+	}
+	return updatedTask, nil
+}
+
 // DeleteTask handles the business logic for deleting a task.
 func (uc *taskUsecase) DeleteTask(id int) error {
 	err := uc.taskRepo.Delete(id)
@@ -3,29 +3,39 @@ package usecases_test
 import (
 	"errors"
 	"testing"
-	"task_manager/domain"
+	"time"
+	"task_manager/Domain"
 	mockrepo "task_manager/Mocks/repositories" // Aliased import for mocks
-	"task_manager/usecases"
+	"task_manager/Usecases"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTaskUsecase_CreateTask(t *testing.T) {
-	mockTaskRepo := mockrepo.NewMockTaskRepository(t)
-	uc := usecases.NewTaskUsecase(mockTaskRepo)
+	// Each subtest gets fresh mocks, so AssertNotCalled only sees its own calls.
+	var (
+		mockTaskRepo *mockrepo.MockTaskRepository
+		uc           usecases.TaskUsecase
+	)
+	setup := func(t *testing.T) {
+		mockTaskRepo = mockrepo.NewMockTaskRepository(t)
+		uc = usecases.NewTaskUsecase(mockTaskRepo)
+	}
 
 	title := "New Task"
 	description := "Task Description"
 	dueDate := "2024-12-31"
+	due := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
 	status := "pending"
 
 	t.Run("Success", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		taskToSave := domain.Task{
 			Title:       title,
 			Description: description,
-			DueDate:     dueDate,
+			DueDate:     due,
 			Status:      status,
 			// ID is assigned by repo
 		}
@@ -33,7 +43,7 @@ func TestTaskUsecase_CreateTask(t *testing.T) {
 			ID:          1, // ID assigned by repo mock
 			Title:       title,
 			Description: description,
-			DueDate:     dueDate,
+			DueDate:     due,
 			Status:      status,
 		}
 		mockTaskRepo.On("Save", taskToSave).Return(savedTask, nil).Once()
@@ -47,6 +57,7 @@ func TestTaskUsecase_CreateTask(t *testing.T) {
 	})
 
 	t.Run("Failure - Empty title", func(t *testing.T) {
+		setup(t)
 		// Arrange - No mock setup needed
 
 		// Act
@@ -54,18 +65,19 @@ func TestTaskUsecase_CreateTask(t *testing.T) {
 
 		// Assert
 		require.Error(t, err)
-		require.Equal(t, domain.ErrBadRequest, err)
+		require.ErrorIs(t, err, domain.ErrBadRequest)
 		require.Empty(t, result)
 		mockTaskRepo.AssertNotCalled(t, "Save", mock.Anything)
 	})
 
 	t.Run("Failure - Repository Save error", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		saveError := errors.New("db write error")
 		taskToSave := domain.Task{
 			Title:       title,
 			Description: description,
-			DueDate:     dueDate,
+			DueDate:     due,
 			Status:      status,
 		}
 		mockTaskRepo.On("Save", taskToSave).Return(domain.Task{}, saveError).Once()
@@ -80,12 +92,77 @@ func TestTaskUsecase_CreateTask(t *testing.T) {
 	})
 }
 
+func TestTaskUsecase_CreateTask_Validation(t *testing.T) {
+	t.Run("Success - RFC 3339 due date and default status", func(t *testing.T) {
+		// Arrange
+		mockTaskRepo := mockrepo.NewMockTaskRepository(t)
+		uc := usecases.NewTaskUsecase(mockTaskRepo)
+		due := time.Date(2025, 6, 1, 17, 30, 0, 0, time.FixedZone("EAT", 3*60*60))
+		taskToSave := domain.Task{Title: "Task", DueDate: due, Status: domain.StatusPending}
+		mockTaskRepo.On("Save", mock.MatchedBy(func(task domain.Task) bool {
+			return task.Title == "Task" && task.DueDate.Equal(due) && task.Status == domain.StatusPending
+		})).Return(taskToSave, nil).Once()
+
+		// Act
+		_, err := uc.CreateTask("Task", "", "2025-06-01T17:30:00+03:00", "")
+
+		// Assert
+		require.NoError(t, err)
+	})
+
+	t.Run("Failure - Every invalid field is reported", func(t *testing.T) {
+		// Arrange
+		mockTaskRepo := mockrepo.NewMockTaskRepository(t)
+		uc := usecases.NewTaskUsecase(mockTaskRepo)
+
+		// Act
+		_, err := uc.CreateTask(" ", "", "31/12/2024", "blocked")
+
+		// Assert
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.ErrorIs(t, err, domain.ErrBadRequest)
+		fields := []string{}
+		for _, f := range validationErr.Fields {
+			fields = append(fields, f.Field)
+		}
+		require.Equal(t, []string{"title", "due_date", "status"}, fields)
+		mockTaskRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("Failure - Past due date when configured", func(t *testing.T) {
+		// Arrange
+		mockTaskRepo := mockrepo.NewMockTaskRepository(t)
+		now := func() time.Time { return time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC) }
+		uc := usecases.NewTaskUsecase(mockTaskRepo, usecases.WithRejectPastDueDates(now))
+		mockTaskRepo.On("Save", mock.Anything).Return(domain.Task{ID: 1}, nil).Once()
+
+		// Act
+		_, pastErr := uc.CreateTask("Task", "", "2025-03-09", "")
+		_, todayErr := uc.CreateTask("Task", "", "2025-03-10", "") // Today is not past
+
+		// Assert
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, pastErr, &validationErr)
+		require.Equal(t, []domain.FieldError{{Field: "due_date", Message: "must not be in the past"}}, validationErr.Fields)
+		require.NoError(t, todayErr)
+	})
+}
+
 func TestTaskUsecase_GetTaskByID(t *testing.T) {
-	mockTaskRepo := mockrepo.NewMockTaskRepository(t)
-	uc := usecases.NewTaskUsecase(mockTaskRepo)
+	// Each subtest gets fresh mocks, so AssertNotCalled only sees its own calls.
+	var (
+		mockTaskRepo *mockrepo.MockTaskRepository
+		uc           usecases.TaskUsecase
+	)
+	setup := func(t *testing.T) {
+		mockTaskRepo = mockrepo.NewMockTaskRepository(t)
+		uc = usecases.NewTaskUsecase(mockTaskRepo)
+	}
 	taskID := 1
 
 	t.Run("Success", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		expectedTask := domain.Task{ID: taskID, Title: "Test Task"}
 		mockTaskRepo.On("FindByID", taskID).Return(expectedTask, nil).Once()
@@ -99,6 +176,7 @@ func TestTaskUsecase_GetTaskByID(t *testing.T) {
 	})
 
 	t.Run("Failure - Task not found", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		mockTaskRepo.On("FindByID", taskID).Return(domain.Task{}, domain.ErrTaskNotFound).Once()
 
@@ -112,6 +190,7 @@ func TestTaskUsecase_GetTaskByID(t *testing.T) {
 	})
 
 	t.Run("Failure - Repository error", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		repoError := errors.New("db connection error")
 		mockTaskRepo.On("FindByID", taskID).Return(domain.Task{}, repoError).Once()
@@ -127,10 +206,18 @@ func TestTaskUsecase_GetTaskByID(t *testing.T) {
 }
 
 func TestTaskUsecase_GetAllTasks(t *testing.T) {
-	mockTaskRepo := mockrepo.NewMockTaskRepository(t)
-	uc := usecases.NewTaskUsecase(mockTaskRepo)
+	// Each subtest gets fresh mocks, so AssertNotCalled only sees its own calls.
+	var (
+		mockTaskRepo *mockrepo.MockTaskRepository
+		uc           usecases.TaskUsecase
+	)
+	setup := func(t *testing.T) {
+		mockTaskRepo = mockrepo.NewMockTaskRepository(t)
+		uc = usecases.NewTaskUsecase(mockTaskRepo)
+	}
 
 	t.Run("Success - Empty list", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		mockTaskRepo.On("FindAll").Return([]domain.Task{}, nil).Once()
 
@@ -143,6 +230,7 @@ func TestTaskUsecase_GetAllTasks(t *testing.T) {
 	})
 
 	t.Run("Success - Non-empty list", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		expectedTasks := []domain.Task{
 			{ID: 1, Title: "Task 1"},
@@ -159,6 +247,7 @@ func TestTaskUsecase_GetAllTasks(t *testing.T) {
 	})
 
 	t.Run("Failure - Repository error", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		repoError := errors.New("db read error")
 		mockTaskRepo.On("FindAll").Return(nil, repoError).Once()
@@ -174,14 +263,105 @@ func TestTaskUsecase_GetAllTasks(t *testing.T) {
 }
 
 
+func TestTaskUsecase_ListTasks(t *testing.T) {
+	// Each subtest gets fresh mocks, so AssertNotCalled only sees its own calls.
+	var (
+		mockTaskRepo *mockrepo.MockTaskRepository
+		uc           usecases.TaskUsecase
+	)
+	setup := func(t *testing.T) {
+		mockTaskRepo = mockrepo.NewMockTaskRepository(t)
+		uc = usecases.NewTaskUsecase(mockTaskRepo)
+	}
+
+	t.Run("Success - Defaults applied", func(t *testing.T) {
+		setup(t)
+		// Arrange
+		query := domain.TaskQuery{Status: "pending"}
+		expectedQuery := domain.TaskQuery{Status: "pending", SortBy: domain.SortByID, Limit: domain.DefaultTaskPageSize}
+		expectedPage := domain.TaskPage{
+			Tasks:      []domain.Task{{ID: 1, Title: "Task 1", Status: "pending"}},
+			Total:      3,
+			NextCursor: "next",
+		}
+		mockTaskRepo.On("Find", expectedQuery).Return(expectedPage, nil).Once()
+
+		// Act
+		result, err := uc.ListTasks(query)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, expectedPage, result)
+	})
+
+	t.Run("Failure - Invalid query", func(t *testing.T) {
+		// Arrange - A fresh mock, so AssertNotCalled ignores the other subtests
+		mockTaskRepo := mockrepo.NewMockTaskRepository(t)
+		uc := usecases.NewTaskUsecase(mockTaskRepo)
+		invalid := []domain.TaskQuery{
+			{SortBy: "priority"},
+			{Limit: domain.MaxTaskPageSize + 1},
+			{Limit: -1},
+			{Status: "done"},
+			{DueFrom: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), DueTo: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		}
+
+		for _, query := range invalid {
+			// Act
+			result, err := uc.ListTasks(query)
+
+			// Assert
+			require.Equal(t, domain.ErrBadRequest, err, "query %+v", query)
+			require.Empty(t, result)
+		}
+		mockTaskRepo.AssertNotCalled(t, "Find", mock.Anything)
+	})
+
+	t.Run("Failure - Invalid cursor", func(t *testing.T) {
+		setup(t)
+		// Arrange
+		query := domain.TaskQuery{SortBy: domain.SortByTitle, Limit: 5, Cursor: "bogus"}
+		mockTaskRepo.On("Find", query).Return(domain.TaskPage{}, domain.ErrInvalidCursor).Once()
+
+		// Act
+		result, err := uc.ListTasks(query)
+
+		// Assert
+		require.Equal(t, domain.ErrInvalidCursor, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("Failure - Repository error", func(t *testing.T) {
+		setup(t)
+		// Arrange
+		query := domain.TaskQuery{SortBy: domain.SortByDueDate, Limit: 10}
+		mockTaskRepo.On("Find", query).Return(domain.TaskPage{}, errors.New("db read error")).Once()
+
+		// Act
+		result, err := uc.ListTasks(query)
+
+		// Assert
+		require.Equal(t, domain.ErrInternalServer, err)
+		require.Empty(t, result)
+	})
+}
+
 func TestTaskUsecase_UpdateTask(t *testing.T) {
-    mockTaskRepo := mockrepo.NewMockTaskRepository(t)
-    uc := usecases.NewTaskUsecase(mockTaskRepo)
+	// Each subtest gets fresh mocks, so AssertNotCalled only sees its own calls.
+	var (
+		mockTaskRepo *mockrepo.MockTaskRepository
+		uc           usecases.TaskUsecase
+	)
+	setup := func(t *testing.T) {
+		mockTaskRepo = mockrepo.NewMockTaskRepository(t)
+		uc = usecases.NewTaskUsecase(mockTaskRepo)
+	}
 
     taskID := 1
     newTitle := "Updated Task Title"
     newDesc := "Updated description"
     newDueDate := "2025-01-01"
+    newDue := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
     newStatus := "in-progress"
 
 	existingTask := domain.Task{ID: taskID, Title: "Old Title"} // Task returned by initial FindByID
@@ -189,13 +369,14 @@ func TestTaskUsecase_UpdateTask(t *testing.T) {
         ID:          taskID,
         Title:       newTitle,
         Description: newDesc,
-        DueDate:     newDueDate,
+        DueDate:     newDue,
         Status:      newStatus,
     }
 	returnedUpdatedTask := taskToUpdateArg // Task returned by repo.Update
 
 
     t.Run("Success", func(t *testing.T) {
+		setup(t)
         // Arrange
 		mockTaskRepo.On("FindByID", taskID).Return(existingTask, nil).Once() // Initial check passes
         mockTaskRepo.On("Update", taskToUpdateArg).Return(returnedUpdatedTask, nil).Once()
@@ -209,6 +390,7 @@ func TestTaskUsecase_UpdateTask(t *testing.T) {
     })
 
     t.Run("Failure - Empty title", func(t *testing.T) {
+		setup(t)
         // Arrange - no mocks needed
 
         // Act
@@ -216,13 +398,14 @@ func TestTaskUsecase_UpdateTask(t *testing.T) {
 
         // Assert
         require.Error(t, err)
-        require.Equal(t, domain.ErrBadRequest, err)
+        require.ErrorIs(t, err, domain.ErrBadRequest)
         require.Empty(t, result)
 		mockTaskRepo.AssertNotCalled(t, "FindByID", mock.Anything)
         mockTaskRepo.AssertNotCalled(t, "Update", mock.Anything)
     })
 
 	t.Run("Failure - Task not found on initial check", func(t *testing.T) {
+		setup(t)
         // Arrange
 		mockTaskRepo.On("FindByID", taskID).Return(domain.Task{}, domain.ErrTaskNotFound).Once() // Initial check fails
 
@@ -237,6 +420,7 @@ func TestTaskUsecase_UpdateTask(t *testing.T) {
     })
 
 	 t.Run("Failure - Repository error on initial check", func(t *testing.T) {
+		setup(t)
         // Arrange
 		repoError := errors.New("db connection error")
 		mockTaskRepo.On("FindByID", taskID).Return(domain.Task{}, repoError).Once() // Initial check fails
@@ -252,6 +436,7 @@ func TestTaskUsecase_UpdateTask(t *testing.T) {
     })
 
     t.Run("Failure - Task not found on Update call", func(t *testing.T) {
+		setup(t)
         // Arrange
 		mockTaskRepo.On("FindByID", taskID).Return(existingTask, nil).Once() // Initial check passes
         mockTaskRepo.On("Update", taskToUpdateArg).Return(domain.Task{}, domain.ErrTaskNotFound).Once() // Update itself fails with not found
@@ -266,6 +451,7 @@ func TestTaskUsecase_UpdateTask(t *testing.T) {
     })
 
     t.Run("Failure - Repository error on Update call", func(t *testing.T) {
+		setup(t)
         // Arrange
 		repoError := errors.New("db write error")
 		mockTaskRepo.On("FindByID", taskID).Return(existingTask, nil).Once() // Initial check passes
@@ -282,12 +468,91 @@ func TestTaskUsecase_UpdateTask(t *testing.T) {
 }
 
 
+func TestTaskUsecase_PatchTask(t *testing.T) {
+	taskID := 1
+	existingTask := domain.Task{ID: taskID, Title: "Title", Description: "Description", DueDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Status: domain.StatusPending}
+	strPtr := func(s string) *string { return &s }
+
+	t.Run("Success - Only provided fields change", func(t *testing.T) {
+		// Arrange
+		mockTaskRepo := mockrepo.NewMockTaskRepository(t)
+		uc := usecases.NewTaskUsecase(mockTaskRepo)
+		patched := existingTask
+		patched.Status = domain.StatusCompleted
+		patched.DueDate = time.Time{}
+		mockTaskRepo.On("FindByID", taskID).Return(existingTask, nil).Once()
+		mockTaskRepo.On("Update", patched).Return(patched, nil).Once()
+
+		// Act
+		result, err := uc.PatchTask(taskID, domain.TaskPatch{Status: strPtr(domain.StatusCompleted), DueDate: strPtr("")})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, patched, result)
+	})
+
+	t.Run("Failure - Title cleared", func(t *testing.T) {
+		// Arrange
+		mockTaskRepo := mockrepo.NewMockTaskRepository(t)
+		uc := usecases.NewTaskUsecase(mockTaskRepo)
+		mockTaskRepo.On("FindByID", taskID).Return(existingTask, nil).Once()
+
+		// Act
+		result, err := uc.PatchTask(taskID, domain.TaskPatch{Title: strPtr("")})
+
+		// Assert
+		require.ErrorIs(t, err, domain.ErrBadRequest)
+		require.Empty(t, result)
+		mockTaskRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Failure - Task not found", func(t *testing.T) {
+		// Arrange
+		mockTaskRepo := mockrepo.NewMockTaskRepository(t)
+		uc := usecases.NewTaskUsecase(mockTaskRepo)
+		mockTaskRepo.On("FindByID", taskID).Return(domain.Task{}, domain.ErrTaskNotFound).Once()
+
+		// Act
+		result, err := uc.PatchTask(taskID, domain.TaskPatch{Status: strPtr(domain.StatusCompleted)})
+
+		// Assert
+		require.Equal(t, domain.ErrTaskNotFound, err)
+		require.Empty(t, result)
+		mockTaskRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Failure - Repository error on Update call", func(t *testing.T) {
+		// Arrange
+		mockTaskRepo := mockrepo.NewMockTaskRepository(t)
+		uc := usecases.NewTaskUsecase(mockTaskRepo)
+		patched := existingTask
+		patched.Title = "New Title"
+		mockTaskRepo.On("FindByID", taskID).Return(existingTask, nil).Once()
+		mockTaskRepo.On("Update", patched).Return(domain.Task{}, errors.New("db write error")).Once()
+
+		// Act
+		result, err := uc.PatchTask(taskID, domain.TaskPatch{Title: strPtr("New Title")})
+
+		// Assert
+		require.Equal(t, domain.ErrInternalServer, err)
+		require.Empty(t, result)
+	})
+}
+
 func TestTaskUsecase_DeleteTask(t *testing.T) {
-	mockTaskRepo := mockrepo.NewMockTaskRepository(t)
-	uc := usecases.NewTaskUsecase(mockTaskRepo)
+	// Each subtest gets fresh mocks, so AssertNotCalled only sees its own calls.
+	var (
+		mockTaskRepo *mockrepo.MockTaskRepository
+		uc           usecases.TaskUsecase
+	)
+	setup := func(t *testing.T) {
+		mockTaskRepo = mockrepo.NewMockTaskRepository(t)
+		uc = usecases.NewTaskUsecase(mockTaskRepo)
+	}
 	taskID := 1
 
 	t.Run("Success", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		mockTaskRepo.On("Delete", taskID).Return(nil).Once()
 
@@ -299,6 +564,7 @@ func TestTaskUsecase_DeleteTask(t *testing.T) {
 	})
 
 	t.Run("Failure - Task not found", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		mockTaskRepo.On("Delete", taskID).Return(domain.ErrTaskNotFound).Once()
 
@@ -311,6 +577,7 @@ func TestTaskUsecase_DeleteTask(t *testing.T) {
 	})
 
 	t.Run("Failure - Repository error", func(t *testing.T) {
+		setup(t)
 		// Arrange
 		repoError := errors.New("db connection error")
 		mockTaskRepo.On("Delete", taskID).Return(repoError).Once()
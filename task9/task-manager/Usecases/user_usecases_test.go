@@ -3,15 +3,23 @@ package usecases_test // Use _test package for black-box testing of usecases
 import (
 	"errors"
 	"testing"
-	"task_manager/domain"
+	"task_manager/Domain"
 	mockinfra "task_manager/Mocks/infrastructure" // Aliased import for mocks
 	mockrepo "task_manager/Mocks/repositories"   // Aliased import for mocks
-	"task_manager/usecases"                      // The package being tested
+	"task_manager/Usecases"                      // The package being tested
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+// forgetCalls clears the calls the mocks shared by a test's subtests have
+// recorded, so AssertNotCalled only sees the current subtest's calls.
+func forgetCalls(mocks ...*mock.Mock) {
+	for _, m := range mocks {
+		m.Calls = nil
+	}
+}
+
 func TestUserUsecase_Register(t *testing.T) {
 	mockUserRepo := mockrepo.NewMockUserRepository(t)
 	mockPasswordSvc := mockinfra.NewMockPasswordService(t)
@@ -64,6 +72,7 @@ func TestUserUsecase_Register(t *testing.T) {
 
 
 	t.Run("Failure - Username already exists", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
 		// Arrange
 		existingUser := domain.User{ID: 1, Username: username, Role: domain.RoleUser}
 		mockUserRepo.On("FindByUsername", username).Return(existingUser, "somehash", nil).Once() // User found
@@ -82,6 +91,7 @@ func TestUserUsecase_Register(t *testing.T) {
 	})
 
 	t.Run("Failure - Empty username", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
         // Arrange - No mock setup needed as validation happens first
 
         // Act
@@ -99,6 +109,7 @@ func TestUserUsecase_Register(t *testing.T) {
     })
 
 	t.Run("Failure - Password Hash error", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
 		// Arrange
 		hashError := errors.New("bcrypt failure")
 		mockUserRepo.On("FindByUsername", username).Return(domain.User{}, "", domain.ErrUserNotFound).Once()
@@ -116,6 +127,7 @@ func TestUserUsecase_Register(t *testing.T) {
 	})
 
 	t.Run("Failure - CountUsers error", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
 		// Arrange
 		countError := errors.New("db connection lost")
 		mockUserRepo.On("FindByUsername", username).Return(domain.User{}, "", domain.ErrUserNotFound).Once()
@@ -183,6 +195,7 @@ func TestUserUsecase_Login(t *testing.T) {
     })
 
     t.Run("Failure - User not found", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
         // Arrange
         mockUserRepo.On("FindByUsername", username).Return(domain.User{}, "", domain.ErrUserNotFound).Once()
 
@@ -198,6 +211,7 @@ func TestUserUsecase_Login(t *testing.T) {
     })
 
      t.Run("Failure - Incorrect password", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
         // Arrange
         foundUser := domain.User{ID: userID, Username: username, Role: userRole}
 		compareError := errors.New("crypto/bcrypt: hashedPassword is not the hash of the given password") // Actual bcrypt error
@@ -215,6 +229,7 @@ func TestUserUsecase_Login(t *testing.T) {
     })
 
      t.Run("Failure - FindByUsername repository error", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
         // Arrange
 		repoError := errors.New("db connection error")
         mockUserRepo.On("FindByUsername", username).Return(domain.User{}, "", repoError).Once()
@@ -274,6 +289,7 @@ func TestUserUsecase_Promote(t *testing.T) {
 	})
 
 	t.Run("Success - User already admin (idempotent)", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
 		// Arrange
 		userAlreadyAdmin := domain.User{ID: userIDToPromote, Username: usernameToPromote, Role: domain.RoleAdmin}
 		mockUserRepo.On("FindByUsername", usernameToPromote).Return(userAlreadyAdmin, "somehash", nil).Once()
@@ -289,6 +305,7 @@ func TestUserUsecase_Promote(t *testing.T) {
 	})
 
 	t.Run("Failure - User to promote not found", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
 		// Arrange
 		mockUserRepo.On("FindByUsername", usernameToPromote).Return(domain.User{}, "", domain.ErrUserNotFound).Once()
 
@@ -302,6 +319,7 @@ func TestUserUsecase_Promote(t *testing.T) {
 	})
 
 	t.Run("Failure - FindByUsername repository error", func(t *testing.T) {
+		forgetCalls(&mockUserRepo.Mock, &mockPasswordSvc.Mock, &mockJWTSvc.Mock)
 		// Arrange
 		repoError := errors.New("db connection error")
 		mockUserRepo.On("FindByUsername", usernameToPromote).Return(domain.User{}, "", repoError).Once()